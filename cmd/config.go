@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"Go-api/pkg/config"
+)
+
+// loadConfig parses the -config and -set flags shared by every subcommand and
+// returns the validated configuration along with the flags to reload it from
+func loadConfig(command string, args []string) (*config.Config, config.Flags, error) {
+	flags, configFlags := newFlagSet(command)
+	if err := flags.Parse(args); err != nil {
+		return nil, *configFlags, err
+	}
+	cfg, err := configFlags.Load()
+	return cfg, *configFlags, err
+}
+
+// newFlagSet returns the flags of a subcommand with -config and -set registered
+func newFlagSet(command string) (*flag.FlagSet, *config.Flags) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	configFlags := &config.Flags{}
+	configFlags.Register(flags)
+	return flags, configFlags
+}
+
+// runConfig prints the effective configuration with secrets redacted:
+//
+//	main config print [-config path] [-set key=value]...
+func runConfig(out io.Writer, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: config print [-config path] [-set key=value]")
+	}
+
+	cfg, _, err := loadConfig("config print", args[1:])
+	if err != nil {
+		return err
+	}
+
+	data, err := cfg.Redacted()
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
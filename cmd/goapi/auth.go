@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+func loginCommand() *command {
+	var email string
+	var passwordStdin bool
+	return &command{
+		name:    "login",
+		summary: "Sign in and store the session",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&email, "email", "", "email to sign in with, prompted for if empty")
+			flags.BoolVar(&passwordStdin, "password-stdin", false, "read the password from the first line of stdin instead of prompting")
+		},
+		run: func(e *env, args []string) error {
+			reader := bufio.NewReader(e.stdin)
+			if email == "" {
+				fmt.Fprint(e.stderr, "Email: ")
+				line, err := reader.ReadString('\n')
+				if err != nil && line == "" {
+					return fmt.Errorf("failed to read email: %w", err)
+				}
+				email = strings.TrimSpace(line)
+			}
+			password, err := e.readPassword(reader, !passwordStdin)
+			if err != nil {
+				return err
+			}
+
+			c, creds, err := e.client()
+			if err != nil {
+				return err
+			}
+			if _, err := c.SignIn(context.Background(), email, password); err != nil {
+				return err
+			}
+			creds.Email = email
+			if err := e.saveCredentials(creds); err != nil {
+				return err
+			}
+			return e.message("signed in to " + c.BaseURL() + " as " + email)
+		},
+	}
+}
+
+// readPassword reads a line from stdin. When prompting on a terminal the input
+// is not echoed.
+func (e *env) readPassword(reader *bufio.Reader, prompt bool) (string, error) {
+	if prompt {
+		fmt.Fprint(e.stderr, "Password: ")
+		if e.stdin == os.Stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+			password, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(e.stderr)
+			if err != nil {
+				return "", fmt.Errorf("failed to read password: %w", err)
+			}
+			return string(password), nil
+		}
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func logoutCommand() *command {
+	return &command{
+		name:    "logout",
+		summary: "Forget the stored session",
+		run: func(e *env, args []string) error {
+			if err := e.deleteCredentials(); err != nil {
+				return err
+			}
+			return e.message("signed out")
+		},
+	}
+}
+
+func tokenCommand() *command {
+	return &command{
+		name:    "token",
+		summary: "Show, refresh and print the stored tokens",
+		subcommands: []*command{
+			{
+				name:    "show",
+				summary: "Show the stored session and when its access token expires",
+				run: func(e *env, args []string) error {
+					_, creds, err := e.client()
+					if err != nil {
+						return err
+					}
+					return e.printSession(creds)
+				},
+			},
+			{
+				name:    "refresh",
+				summary: "Replace the access token using the refresh token",
+				run: func(e *env, args []string) error {
+					c, creds, err := e.client()
+					if err != nil {
+						return err
+					}
+					if err := c.Refresh(context.Background()); err != nil {
+						return err
+					}
+					return e.printSession(creds)
+				},
+			},
+			{
+				name:    "print",
+				summary: "Print the access token, e.g. for curl -H \"Authorization: Bearer $(goapi token print)\"",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					token := c.Tokens().AccessToken
+					if token == "" {
+						return fmt.Errorf("not signed in to %s, run goapi login", c.BaseURL())
+					}
+					_, err = fmt.Fprintln(e.stdout, token)
+					return err
+				},
+			},
+		},
+	}
+}
+
+// session is the output of token show and token refresh; it never includes the
+// tokens themselves
+type session struct {
+	Server          string     `json:"server"`
+	Email           string     `json:"email"`
+	SignedIn        bool       `json:"signed_in"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	HasRefreshToken bool       `json:"has_refresh_token"`
+}
+
+func (e *env) printSession(creds *credentials) error {
+	s := session{
+		Server:          creds.Server,
+		Email:           creds.Email,
+		SignedIn:        creds.AccessToken != "",
+		ExpiresAt:       expiry(creds.AccessToken),
+		HasRefreshToken: creds.RefreshToken != "",
+	}
+	expires := "-"
+	if s.ExpiresAt != nil {
+		expires = s.ExpiresAt.Local().Format(time.RFC3339)
+		if s.ExpiresAt.Before(time.Now()) {
+			expires += " (expired)"
+		}
+	}
+	return e.print(s, table{
+		header: []string{"SERVER", "EMAIL", "SIGNED IN", "EXPIRES", "REFRESH TOKEN"},
+		rows: [][]string{{
+			orDash(s.Server), orDash(s.Email), yesNo(s.SignedIn), expires, yesNo(s.HasRefreshToken),
+		}},
+	})
+}
+
+// expiry reads the exp claim of a JWT without verifying it, the server does that
+func expiry(token string) *time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return nil
+	}
+	exp := time.Unix(int64(claims.Exp), 0).UTC()
+	return &exp
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func completionCommand() *command {
+	return &command{
+		name:    "completion",
+		args:    "SHELL",
+		summary: "Print the completion script for bash or zsh, e.g. source <(goapi completion bash)",
+		run: func(e *env, args []string) error {
+			switch args[0] {
+			case "bash":
+				return writeCompletion(e.stdout, root(), false)
+			case "zsh":
+				return writeCompletion(e.stdout, root(), true)
+			}
+			return usagef("unknown shell %q, expected bash or zsh", args[0])
+		},
+	}
+}
+
+// writeCompletion writes a bash completion function generated from the command
+// tree. zsh loads it through bashcompinit.
+func writeCompletion(w io.Writer, root *command, zsh bool) error {
+	var b strings.Builder
+	if zsh {
+		b.WriteString("#compdef goapi\nautoload -U +X bashcompinit && bashcompinit\n\n")
+	}
+
+	// Every flag taking a value, so that its value is not read as a command
+	valueFlags := map[string]bool{}
+	var cases strings.Builder
+	var walk func(cmd *command, path []string)
+	walk = func(cmd *command, path []string) {
+		var words []string
+		if len(cmd.subcommands) > 0 {
+			for _, sub := range cmd.subcommands {
+				words = append(words, sub.name)
+				walk(sub, append(path, sub.name))
+			}
+		} else {
+			var env env
+			flags := env.flagSet(cmd, path)
+			flags.VisitAll(func(f *flag.Flag) {
+				words = append(words, "-"+f.Name)
+				if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !boolFlag.IsBoolFlag() {
+					valueFlags["-"+f.Name] = true
+				}
+			})
+			if cmd.name == "completion" {
+				words = append(words, "bash", "zsh")
+			}
+		}
+		fmt.Fprintf(&cases, "\t\t%q) words=%q ;;\n", strings.Join(path[1:], " "), strings.Join(words, " "))
+	}
+	walk(root, []string{root.name})
+
+	flagNames := make([]string, 0, len(valueFlags))
+	for name := range valueFlags {
+		flagNames = append(flagNames, name)
+	}
+	sort.Strings(flagNames)
+
+	fmt.Fprintf(&b, `_goapi() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local path="" words="" skip="" i word
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		word="${COMP_WORDS[i]}"
+		if [[ -n "$skip" ]]; then
+			skip=""
+			continue
+		fi
+		case "$word" in
+		%s) skip=1 ;;
+		-*) ;;
+		*) path="${path:+$path }$word" ;;
+		esac
+	done
+	case "$prev" in
+	-o)
+		COMPREPLY=($(compgen -W "table json yaml" -- "$cur"))
+		return
+		;;
+	%s)
+		COMPREPLY=()
+		return
+		;;
+	esac
+	# Positional arguments follow the command, complete the flags of the
+	# longest command path
+	while :; do
+		case "$path" in
+%s		*)
+			if [[ "$path" == *" "* ]]; then
+				path="${path%% *}"
+				continue
+			fi
+			;;
+		esac
+		break
+	done
+	COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -F _goapi goapi
+`, strings.Join(flagNames, "|"), strings.Join(flagNames, "|"), cases.String())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
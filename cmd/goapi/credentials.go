@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"Go-api/pkg/client"
+)
+
+// credentials is the stored session, written by login and whenever the access
+// token is refreshed
+type credentials struct {
+	Server string `json:"server"`
+	Email  string `json:"email"`
+	client.Tokens
+}
+
+// credentialsPath returns $GOAPI_CREDENTIALS, or goapi/credentials.json in the
+// user's configuration directory
+func credentialsPath() string {
+	if path := os.Getenv("GOAPI_CREDENTIALS"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "goapi", "credentials.json")
+}
+
+// loadCredentials returns the stored session, empty if there is none
+func (e *env) loadCredentials() (*credentials, error) {
+	creds := &credentials{}
+	data, err := os.ReadFile(e.credentialsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return creds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", e.credentialsPath, err)
+	}
+	return creds, nil
+}
+
+// saveCredentials writes the session readable by the current user only. The
+// file is replaced atomically so that a failed write keeps the previous one.
+func (e *env) saveCredentials(creds *credentials) error {
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.credentialsPath), 0o700); err != nil {
+		return err
+	}
+	tmp := e.credentialsPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.credentialsPath)
+}
+
+// deleteCredentials forgets the stored session
+func (e *env) deleteCredentials() error {
+	err := os.Remove(e.credentialsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// client returns a client for the selected server, signed in with the stored
+// session if it was made with that server. Refreshed tokens are stored again.
+func (e *env) client() (*client.Client, *credentials, error) {
+	creds, err := e.loadCredentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	server := e.server
+	if server == "" {
+		server = creds.Server
+	}
+	if server == "" {
+		server = defaultServer
+	}
+
+	var opts []client.Option
+	if creds.Server == server {
+		opts = append(opts, client.WithTokens(creds.Tokens))
+	}
+	opts = append(opts, client.OnTokens(func(tokens client.Tokens) {
+		if creds.Server != server {
+			*creds = credentials{Server: server}
+		}
+		creds.Tokens = tokens
+		if err := e.saveCredentials(creds); err != nil {
+			fmt.Fprintln(e.stderr, "goapi: failed to store credentials:", err)
+		}
+	}))
+	return client.New(server, opts...), creds, nil
+}
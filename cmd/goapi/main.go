@@ -0,0 +1,215 @@
+// Command goapi is a command-line client for administrators of the API. It signs
+// in once and stores the tokens, then manages organizations and their members
+// through pkg/client:
+//
+//	goapi login -server http://localhost:8080 -email ada@example.com
+//	goapi org list -o yaml
+//	goapi member invite ORGANIZATION_ID grace@example.com
+//	source <(goapi completion bash)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"Go-api/pkg/client"
+)
+
+// defaultServer is used until a server is given with -server or GOAPI_SERVER
+const defaultServer = "http://localhost:8080"
+
+// command is a node of the command tree. Commands either run or group
+// subcommands; the tree also drives usage messages and shell completion.
+type command struct {
+	name string
+	// args names the positional arguments, e.g. "ID EMAIL"
+	args        string
+	summary     string
+	flags       func(*flag.FlagSet)
+	run         func(e *env, args []string) error
+	subcommands []*command
+}
+
+func (c *command) subcommand(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// root returns the command tree
+func root() *command {
+	return &command{
+		name: "goapi",
+		subcommands: []*command{
+			loginCommand(),
+			logoutCommand(),
+			tokenCommand(),
+			orgCommand(),
+			memberCommand(),
+			completionCommand(),
+		},
+	}
+}
+
+// usageError is reported with the usage of the command it was raised for
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// env carries the global flags and the streams of one run
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	server         string
+	format         string
+	// credentialsPath is where the session is stored
+	credentialsPath string
+}
+
+func main() {
+	e := &env{
+		stdin:           os.Stdin,
+		stdout:          os.Stdout,
+		stderr:          os.Stderr,
+		server:          os.Getenv("GOAPI_SERVER"),
+		format:          formatTable,
+		credentialsPath: credentialsPath(),
+	}
+	os.Exit(e.main(os.Args[1:]))
+}
+
+// main runs args and returns the exit code: 2 for usage errors, 1 for failures
+func (e *env) main(args []string) int {
+	err := e.execute(root(), nil, args)
+	var usageErr *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(e.stderr, "goapi:", err)
+		return 2
+	case errors.Is(err, client.ErrNotSignedIn):
+		fmt.Fprintln(e.stderr, "goapi: not signed in, run goapi login")
+		return 1
+	default:
+		fmt.Fprintln(e.stderr, "goapi:", err)
+		return 1
+	}
+}
+
+// execute finds the command args select under cmd and runs it
+func (e *env) execute(cmd *command, path []string, args []string) error {
+	path = append(path, cmd.name)
+	if len(cmd.subcommands) > 0 {
+		if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+			e.printCommands(cmd, path)
+			if len(args) == 0 {
+				return &usageError{message: "missing command"}
+			}
+			return nil
+		}
+		sub := cmd.subcommand(args[0])
+		if sub == nil {
+			e.printCommands(cmd, path)
+			return usagef("unknown command %q", strings.Join(append(path[1:], args[0]), " "))
+		}
+		return e.execute(sub, path, args[1:])
+	}
+
+	flags := e.flagSet(cmd, path)
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if want := len(strings.Fields(cmd.args)); len(positional) != want {
+		flags.Usage()
+		return usagef("%s expects %d argument(s), got %d", strings.Join(path, " "), want, len(positional))
+	}
+	return cmd.run(e, positional)
+}
+
+// flagSet returns the flags of cmd, including the global ones
+func (e *env) flagSet(cmd *command, path []string) *flag.FlagSet {
+	flags := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.StringVar(&e.server, "server", e.server, "API base URL (default the signed in server or "+defaultServer+", env GOAPI_SERVER)")
+	flags.StringVar(&e.format, "o", e.format, "output format: table, json or yaml")
+	if cmd.flags != nil {
+		cmd.flags(flags)
+	}
+	flags.Usage = func() {
+		usage := "usage: " + strings.Join(path, " ")
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		fmt.Fprintf(e.stderr, "%s [flags]\n\n%s\n\nFlags:\n", usage, cmd.summary)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses flags placed anywhere among the positional arguments, which the
+// flag package alone stops at, and returns the positional arguments
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (e *env) printCommands(cmd *command, path []string) {
+	fmt.Fprintf(e.stderr, "usage: %s <command> [arguments] [flags]\n\nCommands:\n", strings.Join(path, " "))
+	for _, sub := range cmd.subcommands {
+		fmt.Fprintf(e.stderr, "  %-12s %s\n", sub.name, sub.summary)
+	}
+}
+
+// optionalString is a string flag that records whether it was given, so that
+// an empty value can be told apart from a missing one
+type optionalString struct {
+	value string
+	set   bool
+}
+
+func (s *optionalString) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.value
+}
+
+func (s *optionalString) Set(value string) error {
+	s.value, s.set = value, true
+	return nil
+}
+
+// ptr returns the value, or nil if the flag was not given
+func (s *optionalString) ptr() *string {
+	if !s.set {
+		return nil
+	}
+	return &s.value
+}
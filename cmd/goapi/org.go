@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/client"
+)
+
+// organization is the output of the organization commands, with the ETag the
+// writes need
+type organization struct {
+	dto.OrganizationResponse
+	ETag string `json:"etag,omitempty"`
+}
+
+func orgCommand() *command {
+	return &command{
+		name:    "org",
+		summary: "List, show, create, update and delete organizations",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List the organizations you are a member of",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					orgs, err := c.ListOrganizations(context.Background())
+					if err != nil {
+						return err
+					}
+					t := table{header: []string{"ID", "NAME", "MEMBERS", "DESCRIPTION"}}
+					for _, org := range orgs {
+						t.rows = append(t.rows, []string{org.ID, org.Name, strconv.Itoa(len(org.Members)), org.Description})
+					}
+					return e.print(dto.OrganizationListResponse{Organizations: orgs}, t)
+				},
+			},
+			{
+				name:    "get",
+				args:    "ID",
+				summary: "Show an organization",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					org, err := c.GetOrganization(context.Background(), args[0])
+					if err != nil {
+						return err
+					}
+					return e.printOrganization(org)
+				},
+			},
+			orgCreateCommand(),
+			orgUpdateCommand(),
+			orgDeleteCommand(),
+		},
+	}
+}
+
+func orgCreateCommand() *command {
+	var name, description string
+	return &command{
+		name:    "create",
+		summary: "Create an organization with you as its administrator",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&name, "name", "", "name of the organization (required)")
+			flags.StringVar(&description, "description", "", "description of the organization")
+		},
+		run: func(e *env, args []string) error {
+			if name == "" {
+				return usagef("-name is required")
+			}
+			c, _, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			id, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: name, Description: description})
+			if err != nil {
+				return err
+			}
+			org, err := c.GetOrganization(ctx, id)
+			if err != nil {
+				return err
+			}
+			return e.printOrganization(org)
+		},
+	}
+}
+
+func orgUpdateCommand() *command {
+	var name, description optionalString
+	var etag string
+	return &command{
+		name:    "update",
+		args:    "ID",
+		summary: "Change the name or description of an organization",
+		flags: func(flags *flag.FlagSet) {
+			flags.Var(&name, "name", "new name")
+			flags.Var(&description, "description", "new description, empty to clear it")
+			flags.StringVar(&etag, "etag", "", "fail if the organization changed since it was read with this ETag (default the current version)")
+		},
+		run: func(e *env, args []string) error {
+			if !name.set && !description.set {
+				return usagef("nothing to update, give -name or -description")
+			}
+			c, _, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			if etag == "" {
+				if etag, err = currentETag(ctx, c, args[0]); err != nil {
+					return err
+				}
+			}
+			org, err := c.PatchOrganization(ctx, args[0], etag, dto.PatchOrganizationRequest{
+				Name:        name.ptr(),
+				Description: description.ptr(),
+			})
+			if err != nil {
+				return err
+			}
+			return e.printOrganization(org)
+		},
+	}
+}
+
+func orgDeleteCommand() *command {
+	var etag string
+	return &command{
+		name:    "delete",
+		args:    "ID",
+		summary: "Delete an organization",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&etag, "etag", "", "fail if the organization changed since it was read with this ETag (default the current version)")
+		},
+		run: func(e *env, args []string) error {
+			c, _, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			if etag == "" {
+				if etag, err = currentETag(ctx, c, args[0]); err != nil {
+					return err
+				}
+			}
+			if err := c.DeleteOrganization(ctx, args[0], etag); err != nil {
+				return err
+			}
+			return e.message("organization deleted")
+		},
+	}
+}
+
+// currentETag reads the organization for the ETag of its current version
+func currentETag(ctx context.Context, c *client.Client, id string) (string, error) {
+	org, err := c.GetOrganization(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return org.ETag, nil
+}
+
+func (e *env) printOrganization(org *client.Organization) error {
+	t := table{
+		header: []string{"ID", "NAME", "MEMBERS", "DESCRIPTION", "ETAG"},
+		rows: [][]string{{
+			org.ID, org.Name, strconv.Itoa(len(org.Members)), org.Description, org.ETag,
+		}},
+	}
+	return e.print(organization{OrganizationResponse: org.OrganizationResponse, ETag: org.ETag}, t)
+}
+
+func memberCommand() *command {
+	return &command{
+		name:    "member",
+		summary: "List, invite and remove the members of an organization",
+		subcommands: []*command{
+			{
+				name:    "list",
+				args:    "ORGANIZATION_ID",
+				summary: "List the members of an organization",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					org, err := c.GetOrganization(context.Background(), args[0])
+					if err != nil {
+						return err
+					}
+					t := table{header: []string{"NAME", "EMAIL", "ACCESS"}}
+					for _, member := range org.Members {
+						t.rows = append(t.rows, []string{member.Name, member.Email, accessName(member.AccessLevel)})
+					}
+					return e.print(map[string][]dto.MemberResponse{"members": org.Members}, t)
+				},
+			},
+			{
+				name:    "invite",
+				args:    "ORGANIZATION_ID EMAIL",
+				summary: "Add a registered user to an organization",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					if err := c.InviteMember(context.Background(), args[0], args[1]); err != nil {
+						return err
+					}
+					return e.message(args[1] + " invited")
+				},
+			},
+			{
+				name:    "remove",
+				args:    "ORGANIZATION_ID EMAIL",
+				summary: "Remove a member from an organization",
+				run: func(e *env, args []string) error {
+					c, _, err := e.client()
+					if err != nil {
+						return err
+					}
+					if err := c.RemoveMember(context.Background(), args[0], args[1]); err != nil {
+						return err
+					}
+					return e.message(args[1] + " removed")
+				},
+			},
+		},
+	}
+}
+
+// accessName names the access levels the controllers grant
+func accessName(level int) string {
+	if level == 1 {
+		return "admin"
+	}
+	return "member"
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats selected with -o
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// table is the table form of a result, the other formats marshal the value
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes v in the selected format, or t for the table format
+func (e *env) print(v any, t table) error {
+	switch e.format {
+	case formatTable:
+		w := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		if len(t.header) > 0 {
+			fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	case formatJSON:
+		encoder := json.NewEncoder(e.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatYAML:
+		// Go through JSON so that the field names and their order match the API
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var value yaml.MapSlice
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		if data, err = yaml.Marshal(value); err != nil {
+			return err
+		}
+		_, err = e.stdout.Write(data)
+		return err
+	}
+	return usagef("unknown output format %q, expected table, json or yaml", e.format)
+}
+
+// message prints the confirmation of a command that returns nothing
+func (e *env) message(text string) error {
+	return e.print(map[string]string{"message": text}, table{rows: [][]string{{text}}})
+}
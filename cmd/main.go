@@ -1,59 +1,83 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
-	"github.com/gin-gonic/gin"
-
-	"Go-api/pkg/controllers"
-	database "Go-api/pkg/database/mongodb"
-	"Go-api/pkg/database/mongodb/repository"
-	"Go-api/pkg/utils"
+	"Go-api/pkg"
+	"Go-api/pkg/config"
+	"Go-api/pkg/logging"
 )
 
 func main() {
-	// Initialize the logger
-	logger := log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)
+	// Errors before the configuration is loaded are logged with the default settings
+	logger, _, err := logging.New(os.Stdout, config.Default().Logging)
+	if err != nil {
+		panic(err)
+	}
+
+	// The first argument selects a subcommand, the server runs by default
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// Initialize the database
-	db, err := database.NewDB(logger, "config/database-config.yaml")
+	switch command {
+	case "serve":
+		err = runServe(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "config":
+		err = runConfig(os.Stdout, args)
+	case "user":
+		err = runUser(args)
+	case "org":
+		err = runOrg(args)
+	case "seed":
+		err = runSeed(args)
+	case "routes":
+		err = runRoutes(os.Stdout, args)
+	default:
+		logger.Error("unknown command, expected serve, migrate, config, user, org, seed or routes", "command", command)
+		os.Exit(2)
+	}
 	if err != nil {
-		logger.Fatalf("Error initializing database: %v", err)
+		logger.Error("command failed", "command", command, "error", err)
+		os.Exit(1)
 	}
+}
 
-	// Initialize repositories
-	userRepository := repository.NewUserRepository(db.DB)
-	orgRepository := repository.NewOrganizationRepository(db.DB)
-
-	// Initialize controllers
-	userController := controllers.NewUserController(logger, userRepository)
-	orgController := controllers.NewOrganizationController(logger, orgRepository, userRepository)
-	// Set up HTTP server
-	router := gin.Default()
-
-	// Create a router group for user-related routes
-	userRoutes := router.Group("/user")
-	{
-		userRoutes.POST("/signup", userController.SignUp)
-		userRoutes.POST("/signin", userController.SignIn)
-		userRoutes.POST("/refresh", userController.RefreshToken)
+// newLogger builds the root logger from the loaded configuration
+func newLogger(cfg *config.Config) (*slog.Logger, *logging.Levels, error) {
+	return logging.New(os.Stdout, cfg.Logging)
+}
+
+// runServe runs the HTTP server until SIGINT or SIGTERM, then drains in-flight
+// requests and shuts down:
+//
+//	main [serve] [-config path] [-set key=value]...
+func runServe(args []string) error {
+	cfg, configFlags, err := loadConfig("serve", args)
+	if err != nil {
+		return err
 	}
-	// Apply JWT authentication middleware to all routes in the "/organization" group
-	orgRoutes := router.Group("/organization")
-	orgRoutes.Use(utils.AuthMiddleware()) // Apply the middleware here
-
-	// Define your routes
-	orgRoutes.POST("/", orgController.CreateOrg)
-	orgRoutes.GET("/:organization_id", orgController.GetOrgByID)
-	orgRoutes.PUT("/:organization_id", orgController.UpdateOrg)
-	orgRoutes.DELETE("/:organization_id", orgController.DeleteOrg)
-	orgRoutes.POST("/:organization_id/invite", orgController.InviteUser)
-
-	// Start the server
-	logger.Println("Starting server on :8080")
-	err = router.Run(":8080")
+	logger, levels, err := newLogger(cfg)
 	if err != nil {
-		logger.Fatalf("Error starting server: %v", err)
+		return err
 	}
+
+	app, err := pkg.NewApp(logger, levels, cfg, configFlags)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return app.Run(ctx)
 }
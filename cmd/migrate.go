@@ -0,0 +1,35 @@
+package main
+
+import (
+	"Go-api/pkg/database/store"
+	"Go-api/pkg/logging"
+)
+
+// runMigrate applies pending schema migrations and exits:
+//
+//	main migrate [-config path] [-set key=value]... [-dry-run]
+func runMigrate(args []string) error {
+	flags, configFlags := newFlagSet("migrate")
+	dryRun := flags.Bool("dry-run", false, "list pending migrations without applying them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := configFlags.Load()
+	if err != nil {
+		return err
+	}
+
+	logger, _, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.Connect(logging.For(logger, "store"), cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Migrate(*dryRun)
+}
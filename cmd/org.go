@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+)
+
+// runOrg manages organizations without going through the API:
+//
+//	main org transfer-owner -org id -email address [-keep-admins] [-config path] [-set key=value]...
+func runOrg(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: org transfer-owner -org id -email address [flags]")
+	}
+	switch args[0] {
+	case "transfer-owner":
+		return runTransferOwner(args[1:])
+	}
+	return fmt.Errorf("unknown org command %q, expected transfer-owner", args[0])
+}
+
+// runTransferOwner makes a registered user the administrator of an organization,
+// adding them as a member if needed. The previous administrators become regular
+// members unless -keep-admins is set.
+func runTransferOwner(args []string) error {
+	flags, configFlags := newFlagSet("org transfer-owner")
+	orgID := flags.String("org", "", "ID of the organization")
+	email := flags.String("email", "", "email of the new administrator")
+	keepAdmins := flags.Bool("keep-admins", false, "keep the current administrators")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *orgID == "" || *email == "" {
+		return fmt.Errorf("-org and -email are required")
+	}
+
+	db, logger, err := openStore(configFlags)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	org, err := db.Organizations.GetOrganizationByID(ctx, *orgID)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		return repository.ErrOrganizationNotFound
+	}
+	user, err := db.Users.GetUserByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errNoUser(*email)
+	}
+	if !user.Active() {
+		return fmt.Errorf("%s is %s, reinstate them first", user.Email, user.State)
+	}
+
+	members := make([]models.OrganizationMember, 0, len(org.OrganizationMembers)+1)
+	found := false
+	for _, member := range org.OrganizationMembers {
+		switch {
+		case member.Email == user.Email:
+			member.AccessLevel = 1
+			found = true
+		case !*keepAdmins:
+			member.AccessLevel = 0
+		}
+		members = append(members, member)
+	}
+	if !found {
+		members = append(members, models.OrganizationMember{Name: user.Name, Email: user.Email, AccessLevel: 1})
+	}
+
+	// The version check fails if the organization changed since it was read
+	update := &models.Organization{Name: org.Name, Description: org.Description, OrganizationMembers: members}
+	if _, err := db.Organizations.UpdateOrganization(ctx, *orgID, update, org.Version); err != nil {
+		return err
+	}
+	logger.Info("organization ownership transferred", "organization_id", *orgID, "email", user.Email, "keep_admins", *keepAdmins)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/openapi"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+)
+
+// runRoutes prints the routes the server serves with the configuration, without
+// connecting to the database:
+//
+//	main routes [-config path] [-set key=value]...
+func runRoutes(out io.Writer, args []string) error {
+	cfg, _, err := loadConfig("routes", args)
+	if err != nil {
+		return err
+	}
+
+	// The production router, on repositories that are never called
+	gin.SetMode(gin.ReleaseMode)
+	router := routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         memory.NewUserRepository(),
+		Organizations: memory.NewOrganizationRepository(),
+	})
+	info := router.Routes()
+	sort.Slice(info, func(i, j int) bool {
+		if info[i].Path != info[j].Path {
+			return info[i].Path < info[j].Path
+		}
+		return info[i].Method < info[j].Method
+	})
+
+	spec := openapi.Build()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tOPERATION")
+	for _, route := range info {
+		operation := "-"
+		if op := spec.Find(route.Method, route.Path); op != nil {
+			operation = op.OperationID
+			if op.Deprecated {
+				operation += " (deprecated)"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, operation)
+	}
+	return w.Flush()
+}
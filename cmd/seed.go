@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// seedUsers and seedOrganizations are the sample data of the seed command. The
+// first member of each organization is its administrator.
+var (
+	seedUsers = []dto.SignUpRequest{
+		{Name: "Ada Lovelace", Email: "ada@example.com"},
+		{Name: "Grace Hopper", Email: "grace@example.com"},
+		{Name: "Alan Turing", Email: "alan@example.com"},
+	}
+	seedOrganizations = []struct {
+		request dto.OrganizationRequest
+		members []string
+	}{
+		{dto.OrganizationRequest{Name: "Analytical Engines", Description: "Mechanical general-purpose computers"}, []string{"ada@example.com", "alan@example.com"}},
+		{dto.OrganizationRequest{Name: "Compilers", Description: "From English words to machine code"}, []string{"grace@example.com", "ada@example.com"}},
+	}
+)
+
+// runSeed fills the database with sample users and organizations for
+// development. Users and organizations that already exist are left alone, so it
+// can run more than once.
+//
+//	main seed [-password password] [-config path] [-set key=value]...
+func runSeed(args []string) error {
+	flags, configFlags := newFlagSet("seed")
+	password := flags.String("password", "password1", "password of the sample users")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, logger, err := openStore(configFlags)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	users := make(map[string]*models.User, len(seedUsers))
+	for _, req := range seedUsers {
+		req.Password = *password
+		if fields := dto.Validate(&req); len(fields) > 0 {
+			return invalid("sample user", fields)
+		}
+		user, err := db.Users.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			user = req.User()
+			if err := db.Users.CreateUser(ctx, user); err != nil {
+				return fmt.Errorf("failed to create %s: %w", req.Email, err)
+			}
+			logger.Info("user created", "user_id", user.ID.Hex(), "email", user.Email)
+		}
+		users[user.Email] = user
+	}
+
+	for _, seed := range seedOrganizations {
+		existing, err := db.Organizations.GetOrganizationByName(ctx, seed.request.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		org := seed.request.Organization()
+		for i, email := range seed.members {
+			member := models.OrganizationMember{Name: users[email].Name, Email: email}
+			if i == 0 {
+				member.AccessLevel = 1
+			}
+			org.OrganizationMembers = append(org.OrganizationMembers, member)
+		}
+		id, err := db.Organizations.CreateOrganization(ctx, org)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", seed.request.Name, err)
+		}
+		logger.Info("organization created", "organization_id", id, "name", org.Name)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/store"
+	"Go-api/pkg/logging"
+)
+
+// openStore loads the configuration and connects to the database like serve
+// does, applying pending migrations unless database.skip_migrations is set
+func openStore(configFlags *config.Flags) (*store.Store, *slog.Logger, error) {
+	cfg, err := configFlags.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	logger, _, err := newLogger(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := store.New(logging.For(logger, "store"), cfg.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, logger, nil
+}
+
+// invalid describes the fields dto.Validate rejected
+func invalid(what string, fields []problem.FieldError) error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+	return fmt.Errorf("invalid %s: %s", what, strings.Join(messages, "; "))
+}
+
+// readPassword returns the first line of stdin when fromStdin is set, otherwise a
+// random password. Either satisfies the password policy, generated reports which.
+func readPassword(fromStdin bool) (password string, generated bool, err error) {
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, fmt.Errorf("failed to read the password from stdin: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+		if err := dto.ValidatePassword(password); err != nil {
+			return "", false, err
+		}
+		return password, false, nil
+	}
+
+	for {
+		buf := make([]byte, 15)
+		if _, err := rand.Read(buf); err != nil {
+			return "", false, err
+		}
+		password = base64.RawURLEncoding.EncodeToString(buf)
+		// A random string lacks a letter or a digit now and then
+		if dto.ValidatePassword(password) == nil {
+			return password, true, nil
+		}
+	}
+}
+
+// errNoUser is returned for an email that is not registered
+func errNoUser(email string) error {
+	return errors.New("no user with email " + email)
+}
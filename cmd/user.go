@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// runUser manages users without going through the API:
+//
+//	main user create-admin -email address -name name [-password-stdin] [-config path] [-set key=value]...
+//	main user reset-password -email address [-password-stdin] [-config path] [-set key=value]...
+//
+// Without -password-stdin a random password is generated and printed.
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: user create-admin|reset-password -email address [flags]")
+	}
+	switch args[0] {
+	case "create-admin":
+		return runCreateAdmin(args[1:])
+	case "reset-password":
+		return runResetPassword(args[1:])
+	}
+	return fmt.Errorf("unknown user command %q, expected create-admin or reset-password", args[0])
+}
+
+// runCreateAdmin registers the account an operator signs in with first, as a
+// platform admin
+func runCreateAdmin(args []string) error {
+	flags, configFlags := newFlagSet("user create-admin")
+	email := flags.String("email", "", "email of the new user")
+	name := flags.String("name", "Administrator", "name of the new user")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin instead of generating one")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	req := &dto.SignUpRequest{Name: *name, Email: *email, Password: password}
+	if fields := dto.Validate(req); len(fields) > 0 {
+		return invalid("user", fields)
+	}
+
+	db, logger, err := openStore(configFlags)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	user := req.User()
+	user.PlatformAdmin = true
+	if err := db.Users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	logger.Info("platform admin created", "user_id", user.ID.Hex(), "email", user.Email)
+	if generated {
+		fmt.Printf("password: %s\n", password)
+	}
+	return nil
+}
+
+// runResetPassword replaces the password of a user. Tokens issued before stay
+// valid until they expire.
+func runResetPassword(args []string) error {
+	flags, configFlags := newFlagSet("user reset-password")
+	email := flags.String("email", "", "email of the user")
+	passwordStdin := flags.Bool("password-stdin", false, "read the new password from the first line of stdin instead of generating one")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	db, logger, err := openStore(configFlags)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	user, err := db.Users.GetUserByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errNoUser(*email)
+	}
+	if err := db.Users.UpdateUser(ctx, user.ID.Hex(), &models.User{Name: user.Name, Email: user.Email, Password: password}); err != nil {
+		return err
+	}
+	logger.Info("password reset", "user_id", user.ID.Hex(), "email", user.Email)
+	if generated {
+		fmt.Printf("password: %s\n", password)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package dto defines the request and response bodies of the API. They are kept
+// apart from the persistence models so the wire format and validation rules can
+// change without touching storage, and the other way around.
+package dto
+
+import "Go-api/pkg/database/mongodb/models"
+
+// SignUpRequest is the body of POST /user/signup
+type SignUpRequest struct {
+	Name     string `json:"name" binding:"required,notblank,max=100"`
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+// User returns the user to create
+func (r *SignUpRequest) User() *models.User {
+	return &models.User{Name: r.Name, Email: r.Email, Password: r.Password}
+}
+
+// SignInRequest is the body of POST /user/signin
+type SignInRequest struct {
+	Email    string `json:"email" binding:"required,max=254"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// RefreshRequest is the body of POST /user/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// OrganizationRequest is the body of POST /organization and PUT /organization/:id
+type OrganizationRequest struct {
+	Name        string `json:"name" binding:"required,orgname"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// Organization returns the organization to store
+func (r *OrganizationRequest) Organization() *models.Organization {
+	return &models.Organization{Name: r.Name, Description: r.Description}
+}
+
+// PatchOrganizationRequest is the body of PATCH /organization/:id, only the
+// fields present are changed
+type PatchOrganizationRequest struct {
+	Name        *string `json:"name" binding:"omitempty,orgname"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// Apply returns org with the patch applied
+func (r *PatchOrganizationRequest) Apply(org *models.Organization) *models.Organization {
+	patched := &models.Organization{Name: org.Name, Description: org.Description}
+	if r.Name != nil {
+		patched.Name = *r.Name
+	}
+	if r.Description != nil {
+		patched.Description = *r.Description
+	}
+	return patched
+}
+
+// InviteRequest is the body of POST /organization/:id/invite
+type InviteRequest struct {
+	UserEmail string `json:"user_email" binding:"required,email,max=254"`
+}
+
+// ImpersonateRequest is the body of POST /admin/users/:id/impersonate. The reason
+// is written to the audit log.
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,notblank,max=500"`
+}
@@ -0,0 +1,194 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// Response is implemented only by the response types of this package. Render
+// accepts nothing else, so a persistence model, with its password hash or
+// internal fields, can never be written to a client by accident.
+type Response interface {
+	response()
+}
+
+// Render writes resp as the JSON body
+func Render(ctx *gin.Context, status int, resp Response) {
+	ctx.JSON(status, resp)
+}
+
+// MessageResponse confirms an operation that returns no resource
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// UserResponse is the public representation of a user
+type UserResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// NewUserResponse copies the public fields of user
+func NewUserResponse(user *models.User) *UserResponse {
+	return &UserResponse{ID: user.ID.Hex(), Name: user.Name, Email: user.Email}
+}
+
+// TokenResponse is returned by sign-in and refresh. Refresh only issues an
+// access token.
+type TokenResponse struct {
+	Message      string `json:"message"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// MemberResponse is a member of an organization
+type MemberResponse struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	AccessLevel int    `json:"access_level"`
+}
+
+// OrganizationResponse is the public representation of an organization. Its
+// version is sent in the ETag header rather than the body.
+type OrganizationResponse struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Members     []MemberResponse `json:"members"`
+}
+
+// NewOrganizationResponse copies the public fields of org
+func NewOrganizationResponse(org *models.Organization) *OrganizationResponse {
+	resp := &OrganizationResponse{
+		ID:          org.ID.Hex(),
+		Name:        org.Name,
+		Description: org.Description,
+		Members:     make([]MemberResponse, 0, len(org.OrganizationMembers)),
+	}
+	for _, member := range org.OrganizationMembers {
+		resp.Members = append(resp.Members, MemberResponse{
+			Name:        member.Name,
+			Email:       member.Email,
+			AccessLevel: member.AccessLevel,
+		})
+	}
+	return resp
+}
+
+// OrganizationListResponse lists the organizations the current user is a member of
+type OrganizationListResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+}
+
+// NewOrganizationListResponse copies the public fields of orgs
+func NewOrganizationListResponse(orgs []models.Organization) *OrganizationListResponse {
+	resp := &OrganizationListResponse{Organizations: make([]OrganizationResponse, 0, len(orgs))}
+	for i := range orgs {
+		resp.Organizations = append(resp.Organizations, *NewOrganizationResponse(&orgs[i]))
+	}
+	return resp
+}
+
+// OrganizationCreatedResponse is returned by POST /organization
+type OrganizationCreatedResponse struct {
+	OrganizationID string `json:"organization_id"`
+}
+
+// AdminUserResponse is a user as platform admins see it
+type AdminUserResponse struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	PlatformAdmin bool   `json:"platform_admin"`
+	// State is active, suspended or deactivated
+	State string `json:"state"`
+}
+
+// NewAdminUserResponse copies the fields of user platform admins may see
+func NewAdminUserResponse(user *models.User) *AdminUserResponse {
+	return &AdminUserResponse{
+		ID:            user.ID.Hex(),
+		Name:          user.Name,
+		Email:         user.Email,
+		PlatformAdmin: user.PlatformAdmin,
+		State:         user.State,
+	}
+}
+
+// UserListResponse lists users for platform admins
+type UserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+}
+
+// NewUserListResponse copies the fields of users platform admins may see
+func NewUserListResponse(users []models.User) *UserListResponse {
+	resp := &UserListResponse{Users: make([]AdminUserResponse, 0, len(users))}
+	for i := range users {
+		resp.Users = append(resp.Users, *NewAdminUserResponse(&users[i]))
+	}
+	return resp
+}
+
+// AdminOrganizationResponse is an organization as platform admins see it,
+// including force-deleted ones
+type AdminOrganizationResponse struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Members     []MemberResponse `json:"members"`
+	Version     int64            `json:"version"`
+	DeletedAt   *time.Time       `json:"deleted_at,omitempty"`
+}
+
+// NewAdminOrganizationResponse copies the fields of org platform admins may see
+func NewAdminOrganizationResponse(org *models.Organization) *AdminOrganizationResponse {
+	public := NewOrganizationResponse(org)
+	return &AdminOrganizationResponse{
+		ID:          public.ID,
+		Name:        public.Name,
+		Description: public.Description,
+		Members:     public.Members,
+		Version:     org.Version,
+		DeletedAt:   org.DeletedAt,
+	}
+}
+
+// AdminOrganizationListResponse lists organizations for platform admins
+type AdminOrganizationListResponse struct {
+	Organizations []AdminOrganizationResponse `json:"organizations"`
+}
+
+// NewAdminOrganizationListResponse copies the fields of orgs platform admins may see
+func NewAdminOrganizationListResponse(orgs []models.Organization) *AdminOrganizationListResponse {
+	resp := &AdminOrganizationListResponse{Organizations: make([]AdminOrganizationResponse, 0, len(orgs))}
+	for i := range orgs {
+		resp.Organizations = append(resp.Organizations, *NewAdminOrganizationResponse(&orgs[i]))
+	}
+	return resp
+}
+
+// ImpersonationResponse carries an access token issued to a platform admin to act
+// as another user. There is no refresh token, a new one must be requested once
+// it expires.
+type ImpersonationResponse struct {
+	Message     string       `json:"message"`
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	User        UserResponse `json:"user"`
+}
+
+func (*MessageResponse) response()               {}
+func (*UserResponse) response()                  {}
+func (*TokenResponse) response()                 {}
+func (*OrganizationResponse) response()          {}
+func (*OrganizationListResponse) response()      {}
+func (*OrganizationCreatedResponse) response()   {}
+func (*AdminUserResponse) response()             {}
+func (*UserListResponse) response()              {}
+func (*AdminOrganizationResponse) response()     {}
+func (*AdminOrganizationListResponse) response() {}
+func (*ImpersonationResponse) response()         {}
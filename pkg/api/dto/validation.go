@@ -0,0 +1,137 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"Go-api/pkg/api/problem"
+)
+
+// Password policy, bcrypt ignores everything past 72 bytes
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// OrganizationNamePattern allows letters, digits, spaces and . _ - starting with
+// a letter or digit, 2 to 64 characters in total
+const OrganizationNamePattern = `^[A-Za-z0-9][A-Za-z0-9 ._-]{1,63}$`
+
+var organizationName = regexp.MustCompile(OrganizationNamePattern)
+
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("dto: gin does not use go-playground/validator")
+	}
+
+	// Report fields by their JSON names
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validate.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	validate.RegisterValidation("orgname", func(fl validator.FieldLevel) bool {
+		return organizationName.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return ValidatePassword(fl.Field().String()) == nil
+	})
+}
+
+// ValidatePassword applies the password policy of the password binding rule:
+// 8 to 72 bytes with at least one letter and one digit
+func ValidatePassword(password string) error {
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength || !letter || !digit {
+		return errors.New("password " + RuleMessage("password", ""))
+	}
+	return nil
+}
+
+// Bind decodes and validates the JSON body into req. It writes a problem listing
+// every invalid field and returns false when the request must not proceed.
+func Bind(ctx *gin.Context, req any) bool {
+	err := ctx.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		problem.InvalidRequest(ctx, err)
+		return false
+	}
+
+	p := problem.New(http.StatusUnprocessableEntity, problem.CodeValidationFailed, "request body has invalid fields")
+	p.Errors = fieldErrors(validationErrs)
+	problem.Write(ctx, p)
+	return false
+}
+
+// Validate checks a request built outside of an HTTP request, e.g. from
+// command-line flags, and returns every invalid field
+func Validate(req any) []problem.FieldError {
+	var validationErrs validator.ValidationErrors
+	if err := binding.Validator.ValidateStruct(req); errors.As(err, &validationErrs) {
+		return fieldErrors(validationErrs)
+	}
+	return nil
+}
+
+func fieldErrors(validationErrs validator.ValidationErrors) []problem.FieldError {
+	fields := make([]problem.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, problem.FieldError{
+			Field:   fieldErr.Field(),
+			Code:    fieldErr.Tag(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+	return fields
+}
+
+// fieldMessage describes a failed rule for humans
+func fieldMessage(fieldErr validator.FieldError) string {
+	return RuleMessage(fieldErr.Tag(), fieldErr.Param())
+}
+
+// RuleMessage describes the failed validation rule with its parameter, e.g. max
+// and 100, for humans
+func RuleMessage(rule, param string) string {
+	switch rule {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "email":
+		return "must be a valid email address"
+	case "max":
+		return fmt.Sprintf("must be at most %s characters long", param)
+	case "min":
+		return fmt.Sprintf("must be at least %s characters long", param)
+	case "orgname":
+		return "must be 2 to 64 letters, digits, spaces, dots, underscores or hyphens, starting with a letter or digit"
+	case "password":
+		return fmt.Sprintf("must be %d to %d characters long and contain a letter and a digit", MinPasswordLength, MaxPasswordLength)
+	}
+	return "is invalid"
+}
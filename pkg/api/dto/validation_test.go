@@ -0,0 +1,34 @@
+package dto_test
+
+import (
+	"strings"
+	"testing"
+
+	"Go-api/pkg/api/dto"
+)
+
+// TestValidatePassword checks the CLI and the password binding rule agree
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"secret12", true},
+		{"pässwörd1", true},
+		{"short1", false},
+		{"longenough", false},
+		{"1234567890", false},
+		{strings.Repeat("a", 71) + "1", true},
+		{strings.Repeat("a", 72) + "1", false},
+	}
+	for _, tt := range tests {
+		err := dto.ValidatePassword(tt.password)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidatePassword(%q) = %v, want valid %v", tt.password, err, tt.valid)
+		}
+		fields := dto.Validate(&dto.SignUpRequest{Name: "Ada", Email: "ada@example.com", Password: tt.password})
+		if (len(fields) == 0) != tt.valid {
+			t.Errorf("binding rule on %q = %v, want valid %v", tt.password, fields, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package handlers contains HTTP handlers that are not tied to a business resource
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check probes one dependency. Critical checks gate readiness, the others only
+// appear in the detailed report.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+// HealthHandler serves the liveness, readiness and detailed health endpoints.
+// Probe errors can name hosts and other internals, so they are logged and the
+// responses only tell whether a check failed or timed out.
+type HealthHandler struct {
+	logger       *slog.Logger
+	checks       []Check
+	timeout      time.Duration
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler runs every probe with the given timeout
+func NewHealthHandler(logger *slog.Logger, timeout time.Duration, checks ...Check) *HealthHandler {
+	return &HealthHandler{logger: logger, checks: checks, timeout: timeout, startedAt: time.Now()}
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop sending traffic
+// while in-flight requests drain
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Causes of a failed check in the responses
+const (
+	causeFailed   = "failed"
+	causeTimedOut = "timed out"
+)
+
+type checkResult struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// run probes the checks concurrently
+func (h *HealthHandler) run(ctx context.Context, criticalOnly bool) (map[string]checkResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]checkResult)
+	healthy := true
+	for _, check := range h.checks {
+		if criticalOnly && !check.Critical {
+			continue
+		}
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Probe(ctx)
+			result := checkResult{
+				Status:    "up",
+				Critical:  check.Critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				h.logger.WarnContext(ctx, "health check failed", "check", check.Name, "critical", check.Critical, "error", err)
+				result.Status = "down"
+				result.Error = causeFailed
+				if errors.Is(err, context.DeadlineExceeded) {
+					result.Error = causeTimedOut
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[check.Name] = result
+			if err != nil && check.Critical {
+				healthy = false
+			}
+		}(check)
+	}
+	wg.Wait()
+	return results, healthy
+}
+
+// Liveness reports that the process is up and serving requests
+func (h *HealthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether the instance should receive traffic: critical
+// dependencies are up and it is not shutting down
+func (h *HealthHandler) Readiness(ctx *gin.Context) {
+	if h.shuttingDown.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+
+	results, healthy := h.run(ctx.Request.Context(), true)
+	if !healthy {
+		failures := make(map[string]string)
+		for name, result := range results {
+			if result.Status != "up" {
+				failures[name] = result.Error
+			}
+		}
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": failures})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// Report returns every check with its status and latency
+func (h *HealthHandler) Report(ctx *gin.Context) {
+	results, healthy := h.run(ctx.Request.Context(), false)
+
+	status, code := "up", http.StatusOK
+	if !healthy {
+		status, code = "down", http.StatusServiceUnavailable
+	} else if h.shuttingDown.Load() {
+		status, code = "shutting down", http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(code, gin.H{
+		"status":         status,
+		"uptime_seconds": int64(time.Since(h.startedAt).Seconds()),
+		"checks":         results,
+	})
+}
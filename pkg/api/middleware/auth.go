@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/logging"
+	"Go-api/pkg/metrics"
+	"Go-api/pkg/utils"
+)
+
+// UserLookup loads the user a token was issued to, repository.UserRepository
+// satisfies it
+type UserLookup interface {
+	GetUser(ctx context.Context, id string) (*models.User, error)
+}
+
+// Authenticate rejects requests without a valid access token, or whose user is
+// gone or no longer active. keys is called on every request so rotated keys take
+// effect immediately. The user is stored in the context under "user". Outcomes
+// are counted in m, which may be nil.
+func Authenticate(keys func() utils.JWTKeys, users UserLookup, m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Extract the JWT token from the request header
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeMissingToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeMissingToken, "missing authorization token")
+			return
+		}
+		_, tokenString, ok := strings.Cut(authHeader, " ")
+		if !ok {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeInvalidToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeInvalidToken, "invalid authorization token")
+			return
+		}
+
+		// Parse and validate the token
+		claims, err := utils.ParseToken(tokenString, keys())
+		if errors.Is(err, utils.ErrTokenExpired) {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeExpiredToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeTokenExpired, "token has expired")
+			return
+		}
+		if err != nil {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeInvalidToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeInvalidToken, "invalid authorization token")
+			return
+		}
+
+		// Tokens without an expiry are treated as expired
+		exp, ok := claims["exp"].(float64)
+		if !ok || time.Unix(int64(exp), 0).Before(time.Now()) {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeExpiredToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeTokenExpired, "token has expired")
+			return
+		}
+
+		userID, ok := claims["user_id"].(string)
+		if !ok {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeInvalidToken)
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeInvalidToken, "invalid authorization token")
+			return
+		}
+
+		// The account is checked on every request, so suspending, deactivating or
+		// deleting a user takes effect before their tokens expire
+		user, err := users.GetUser(c.Request.Context(), userID)
+		if err != nil {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeError)
+			problem.Error(c, err)
+			return
+		}
+		if user == nil {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeInvalidToken)
+			problem.Error(c, repository.ErrUnknownUser)
+			return
+		}
+		if err := repository.StateError(user); err != nil {
+			m.Auth(metrics.AuthAccessToken, metrics.OutcomeInactiveAccount)
+			problem.Error(c, err)
+			return
+		}
+
+		attrs := []slog.Attr{slog.String("user_id", userID)}
+		// Impersonation tokens name the platform admin acting as the user, every
+		// request they make is logged with it. They stop working as soon as the
+		// admin loses their rights.
+		if impersonator, ok := claims[utils.ImpersonatorClaim].(string); ok {
+			admin, err := users.GetUser(c.Request.Context(), impersonator)
+			if err != nil {
+				m.Auth(metrics.AuthAccessToken, metrics.OutcomeError)
+				problem.Error(c, err)
+				return
+			}
+			if admin == nil || !admin.PlatformAdmin || !admin.Active() {
+				m.Auth(metrics.AuthAccessToken, metrics.OutcomeInvalidToken)
+				problem.Abort(c, http.StatusUnauthorized, problem.CodeInvalidToken, "the impersonation has been revoked")
+				return
+			}
+			c.Set("impersonator_id", impersonator)
+			attrs = append(attrs, slog.String("impersonator_id", impersonator))
+		}
+
+		// If the token is valid, proceed to the next handler
+		m.Auth(metrics.AuthAccessToken, metrics.OutcomeSuccess)
+		c.Set("user_id", userID) // Set user_id in context for further use
+		c.Set("user", user)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), attrs...))
+		c.Next()
+	}
+}
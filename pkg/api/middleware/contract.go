@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/openapi"
+	"Go-api/pkg/api/problem"
+)
+
+// Contract checks requests, and responses when validateResponses is set, against
+// the operation doc describes for the route. Requests that do not match are
+// rejected before the handler runs: 400 invalid_request for malformed bodies and
+// parameters, 415 for a body that is not JSON, 422 listing every invalid field
+// like dto.Bind and 428 for a missing If-Match. Responses that do not match are
+// logged at error level; they are still sent as they are. Routes doc does not
+// describe pass through unchecked.
+func Contract(doc *openapi.Document, logger *slog.Logger, validateRequests, validateResponses bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		op := doc.Find(ctx.Request.Method, ctx.FullPath())
+		if op == nil {
+			ctx.Next()
+			return
+		}
+
+		if validateRequests && !checkRequest(ctx, doc, op) {
+			return
+		}
+		if !validateResponses {
+			ctx.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+		ctx.Writer = recorder.ResponseWriter
+
+		// Nothing was sent to a client that went away
+		if recorder.Status() == problem.StatusClientClosedRequest {
+			return
+		}
+		if violations := checkResponse(doc, op, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); len(violations) > 0 {
+			logger.ErrorContext(ctx.Request.Context(), "response does not match the OpenAPI document",
+				"operation", op.OperationID,
+				"status", recorder.Status(),
+				"violations", violations,
+			)
+		}
+	}
+}
+
+// checkRequest writes the problem and returns false when the request does not
+// match op
+func checkRequest(ctx *gin.Context, doc *openapi.Document, op *openapi.Operation) bool {
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value = ctx.Param(param.Name)
+			present = value != ""
+		case "header":
+			value = ctx.GetHeader(param.Name)
+			present = value != ""
+		case "query":
+			value, present = ctx.GetQuery(param.Name)
+		default:
+			continue
+		}
+
+		if !present {
+			if !param.Required {
+				continue
+			}
+			if strings.EqualFold(param.Name, "If-Match") {
+				problem.Abort(ctx, http.StatusPreconditionRequired, problem.CodePreconditionRequired, "If-Match header is required")
+				return false
+			}
+			problem.Abort(ctx, http.StatusBadRequest, problem.CodeInvalidRequest, param.In+" parameter "+param.Name+" is required")
+			return false
+		}
+		if violations := doc.Validate(param.Schema, value); len(violations) > 0 {
+			problem.Abort(ctx, http.StatusBadRequest, problem.CodeInvalidRequest, param.In+" parameter "+param.Name+" "+violations[0].Message)
+			return false
+		}
+	}
+
+	if op.RequestBody == nil {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		problem.Abort(ctx, http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, "request body must be application/json")
+		return false
+	}
+
+	// Read the body and put it back for the handler
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		problem.InvalidRequest(ctx, err)
+		return false
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			problem.InvalidRequest(ctx, io.EOF)
+			return false
+		}
+		return true
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		problem.InvalidRequest(ctx, err)
+		return false
+	}
+
+	violations := doc.Validate(content.Schema, value)
+	if len(violations) == 0 {
+		return true
+	}
+	// A value of the wrong type cannot be decoded at all, like in dto.Bind
+	for _, violation := range violations {
+		if violation.Rule == "type" {
+			problem.Abort(ctx, http.StatusBadRequest, problem.CodeInvalidRequest, "field "+violation.String())
+			return false
+		}
+	}
+	p := problem.New(http.StatusUnprocessableEntity, problem.CodeValidationFailed, "request body has invalid fields")
+	for _, violation := range violations {
+		p.Errors = append(p.Errors, problem.FieldError{
+			Field:   violation.Path,
+			Code:    violation.Rule,
+			Message: violation.Message,
+		})
+	}
+	problem.Write(ctx, p)
+	return false
+}
+
+// checkResponse returns how a response breaks op
+func checkResponse(doc *openapi.Document, op *openapi.Operation, status int, contentType string, body []byte) []string {
+	response := doc.Response(op, status)
+	if response == nil {
+		return []string{"status " + strconv.Itoa(status) + " is not documented"}
+	}
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return []string{"body is not documented"}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return []string{"content type " + mediaType + " is not documented"}
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{"body is not valid JSON"}
+	}
+	var problems []string
+	for _, violation := range doc.Validate(content.Schema, value) {
+		problems = append(problems, violation.String())
+	}
+	return problems
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
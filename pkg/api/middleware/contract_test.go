@@ -0,0 +1,158 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/middleware"
+	"Go-api/pkg/api/openapi"
+	"Go-api/pkg/api/problem"
+)
+
+// violations collects the violations the Contract middleware logs
+type violations struct {
+	mu  sync.Mutex
+	all [][]string
+}
+
+func (v *violations) Enabled(context.Context, slog.Level) bool { return true }
+
+func (v *violations) Handle(_ context.Context, record slog.Record) error {
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "violations" {
+			v.mu.Lock()
+			v.all = append(v.all, attr.Value.Any().([]string))
+			v.mu.Unlock()
+		}
+		return true
+	})
+	return nil
+}
+
+func (v *violations) WithAttrs([]slog.Attr) slog.Handler { return v }
+
+func (v *violations) WithGroup(string) slog.Handler { return v }
+
+// contractRouter serves sign-up and organization updates behind Contract,
+// answering with the status and body in the X-Respond-* headers of the request,
+// 200 and no body by default
+func contractRouter(logged *violations) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Contract(openapi.Build(), slog.New(logged), true, true))
+	respond := func(ctx *gin.Context) {
+		status, err := strconv.Atoi(ctx.GetHeader("X-Respond-Status"))
+		if err != nil {
+			status = http.StatusOK
+		}
+		ctx.Data(status, "application/json", []byte(ctx.GetHeader("X-Respond-Body")))
+	}
+	router.POST("/v1/user/signup", respond)
+	router.PUT("/v1/organization/:organization_id", respond)
+	return router
+}
+
+func TestContractRequests(t *testing.T) {
+	const valid = `{"name": "Ada", "email": "ada@example.com", "password": "password1"}`
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		header      map[string]string
+		body        string
+		status      int
+		code        string
+		fields      []problem.FieldError
+	}{
+		{name: "valid", method: http.MethodPost, path: "/v1/user/signup", contentType: "application/json", body: valid, status: http.StatusOK},
+		{name: "not JSON", method: http.MethodPost, path: "/v1/user/signup", contentType: "text/plain", body: valid, status: http.StatusUnsupportedMediaType, code: problem.CodeUnsupportedMediaType},
+		{name: "malformed", method: http.MethodPost, path: "/v1/user/signup", contentType: "application/json", body: `{"name":`, status: http.StatusBadRequest, code: problem.CodeInvalidRequest},
+		{name: "empty", method: http.MethodPost, path: "/v1/user/signup", contentType: "application/json", status: http.StatusBadRequest, code: problem.CodeInvalidRequest},
+		{name: "wrong type", method: http.MethodPost, path: "/v1/user/signup", contentType: "application/json", body: `{"name": "Ada", "email": 1, "password": "password1"}`, status: http.StatusBadRequest, code: problem.CodeInvalidRequest},
+		{
+			name: "invalid fields", method: http.MethodPost, path: "/v1/user/signup", contentType: "application/json; charset=utf-8",
+			body:   `{"email": "ada", "password": "short"}`,
+			status: http.StatusUnprocessableEntity, code: problem.CodeValidationFailed,
+			fields: []problem.FieldError{
+				{Field: "name", Code: "required", Message: "is required"},
+				{Field: "email", Code: "email", Message: "must be a valid email address"},
+				{Field: "password", Code: "password", Message: "must be 8 to 72 characters long and contain a letter and a digit"},
+			},
+		},
+		{name: "missing If-Match", method: http.MethodPut, path: "/v1/organization/1", contentType: "application/json", body: `{"name": "Acme"}`, status: http.StatusPreconditionRequired, code: problem.CodePreconditionRequired},
+		{name: "If-Match", method: http.MethodPut, path: "/v1/organization/1", contentType: "application/json", header: map[string]string{"If-Match": `"1"`}, body: `{"name": "Acme"}`, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			contractRouter(&violations{}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("%s %s = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.status, rec.Body)
+			}
+			if tt.code == "" {
+				return
+			}
+			var p problem.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("response is not a problem: %v", err)
+			}
+			if p.Code != tt.code || !reflect.DeepEqual(p.Errors, tt.fields) {
+				t.Errorf("problem = %s %+v, want %s %+v", p.Code, p.Errors, tt.code, tt.fields)
+			}
+		})
+	}
+}
+
+func TestContractResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		body   string
+		want   []string
+	}{
+		{"valid", "201", `{"message": "User created"}`, nil},
+		{"undocumented status", "418", `{}`, []string{"status 418 is not documented"}},
+		{"invalid body", "201", `{"message": 1}`, []string{"message must be of type string"}},
+		{"not JSON", "201", `created`, []string{"body is not valid JSON"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/user/signup", strings.NewReader(`{"name": "Ada", "email": "ada@example.com", "password": "password1"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Respond-Status", tt.status)
+			req.Header.Set("X-Respond-Body", tt.body)
+			logged := &violations{}
+			rec := httptest.NewRecorder()
+			contractRouter(logged).ServeHTTP(rec, req)
+
+			// The response is sent as it is
+			if rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body, tt.body)
+			}
+			var got []string
+			for _, v := range logged.all {
+				got = append(got, v...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logged %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exposedHeaders are the response headers scripts on other origins may read:
+// versions, rate limits, request IDs to report and deprecation notices
+const exposedHeaders = "ETag, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, " + RequestIDHeader + ", Deprecation, Sunset, Link"
+
+// CORS allows browsers on the listed origins to call the API. origins is called on
+// every request so the allowed list can change at runtime; "*" allows any origin.
+func CORS(origins func() []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := false
+		for _, o := range origins() {
+			if o == "*" || o == origin {
+				allowed = true
+				break
+			}
+		}
+		c.Header("Vary", "Origin")
+		if !allowed {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Expose-Headers", exposedHeaders)
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			// Preflight request
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match, "+RequestIDHeader)
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/metrics"
+)
+
+// Deprecation describes routes that are being retired in favour of others
+type Deprecation struct {
+	// Since is when the routes were deprecated
+	Since time.Time
+	// Sunset is when they stop being served, zero while it is not decided
+	Sunset time.Time
+	// SuccessorPrefix is prepended to the request path to form the route that
+	// replaces it, e.g. /v1
+	SuccessorPrefix string
+}
+
+// Deprecated announces the deprecation on every response, errors included: the
+// Deprecation header (RFC 9745), the Sunset header (RFC 8594) and a Link to the
+// successor route. Calls are counted by route so the routes can be removed once
+// nobody uses them.
+func Deprecated(d Deprecation, m *metrics.Metrics) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		header.Set("Deprecation", deprecation)
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		header.Add("Link", "<"+d.SuccessorPrefix+ctx.Request.URL.Path+">; rel=\"successor-version\"")
+
+		m.DeprecatedRequest(ctx.Request.Method, ctx.FullPath())
+		ctx.Next()
+	}
+}
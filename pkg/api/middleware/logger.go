@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/problem"
+)
+
+// Logger writes one access log record per request with its route template,
+// status and latency. The request ID, user and organization come from the
+// request context. Server errors are logged at error level, client errors
+// at warn and everything else at info.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("response_size", ctx.Writer.Size()),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", ctx.Errors.String()))
+		}
+		logger.LogAttrs(ctx.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+// Recovery turns a panicking handler into a 500 and logs the panic with its stack
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.ErrorContext(ctx.Request.Context(), "handler panicked",
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				problem.Abort(ctx, http.StatusInternalServerError, problem.CodeInternalError, "internal server error")
+			}
+		}()
+		ctx.Next()
+	}
+}
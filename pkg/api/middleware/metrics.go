@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/metrics"
+)
+
+// Metrics records the duration of every request labelled by its route template
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		done := m.RequestStarted()
+		defer done()
+
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,67 @@
+// Package middleware contains gin middleware shared by every route
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/logging"
+)
+
+// RequestIDHeader carries the request ID from the client or a proxy and back in
+// the response
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key of the request ID
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds incoming IDs so clients cannot bloat the logs
+const maxRequestIDLength = 128
+
+// RequestID keeps the X-Request-ID of the request, or generates one when it is
+// missing or malformed, echoes it in the response and attaches it to every
+// record logged while serving the request
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		ctx.Set(requestIDKey, id)
+		ctx.Header(RequestIDHeader, id)
+
+		attrs := []slog.Attr{slog.String("request_id", id)}
+		if orgID := ctx.Param("organization_id"); orgID != "" {
+			attrs = append(attrs, slog.String("org_id", orgID))
+		}
+		ctx.Request = ctx.Request.WithContext(logging.WithAttrs(ctx.Request.Context(), attrs...))
+		ctx.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString(requestIDKey)
+}
+
+// validRequestID accepts printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,174 @@
+// Renders the OpenAPI document of the server as a reference page. It is
+// embedded in the binary and served inline, so the page loads nothing from
+// third parties and works without internet access.
+(function () {
+  "use strict";
+
+  var root = document.getElementById("reference");
+  var specURL = root.getAttribute("data-spec-url");
+
+  // el creates an element with a class and text or children
+  function el(tag, className, content) {
+    var node = document.createElement(tag);
+    if (className) node.className = className;
+    if (typeof content === "string") {
+      node.textContent = content;
+    } else if (content) {
+      content.forEach(function (child) { if (child) node.appendChild(child); });
+    }
+    return node;
+  }
+
+  // resolve follows a local $ref, e.g. #/components/schemas/UserResponse
+  function resolve(doc, value) {
+    while (value && value.$ref) {
+      var target = doc;
+      value.$ref.replace(/^#\//, "").split("/").forEach(function (key) {
+        target = target && target[key];
+      });
+      value = target;
+    }
+    return value || {};
+  }
+
+  function refName(value) {
+    return value && value.$ref ? value.$ref.split("/").pop() : "";
+  }
+
+  // typeOf describes a schema in one line
+  function typeOf(doc, schema) {
+    if (schema.$ref) return refName(schema);
+    if (schema.type === "array") return typeOf(doc, schema.items || {}) + "[]";
+    var type = Array.isArray(schema.type) ? schema.type.join(" | ") : (schema.type || "any");
+    if (schema.format) type += " (" + schema.format + ")";
+    if (schema.enum) type += ": " + schema.enum.join(", ");
+    return type;
+  }
+
+  // constraints lists the validation rules of a schema
+  function constraints(schema) {
+    var rules = [];
+    [["minLength", "min length"], ["maxLength", "max length"], ["minimum", "min"],
+     ["maximum", "max"], ["pattern", "pattern"], ["minItems", "min items"]].forEach(function (rule) {
+      if (schema[rule[0]] !== undefined) rules.push(rule[1] + " " + schema[rule[0]]);
+    });
+    return rules.join(", ");
+  }
+
+  // schemaTable renders the properties of an object schema, one level deep;
+  // nested objects are named by their schema
+  function schemaTable(doc, value) {
+    var schema = resolve(doc, value);
+    var name = refName(value) || refName(schema.items);
+    if (schema.type === "array") schema = resolve(doc, schema.items);
+    var properties = schema.properties || {};
+    var required = schema.required || [];
+    var rows = Object.keys(properties).map(function (key) {
+      var property = properties[key];
+      var resolved = resolve(doc, property);
+      return el("tr", "", [
+        el("td", "name", key + (required.indexOf(key) >= 0 ? " *" : "")),
+        el("td", "type", typeOf(doc, property)),
+        el("td", "", [resolved.description || property.description || "", constraints(resolved)].filter(Boolean).join(". "))
+      ]);
+    });
+    return el("div", "schema", [
+      name ? el("div", "schema-name", name) : null,
+      rows.length ? el("table", "", rows) : el("div", "muted", typeOf(doc, schema))
+    ]);
+  }
+
+  function jsonSchema(content) {
+    var media = content && (content["application/json"] || content["application/problem+json"]);
+    return media && media.schema;
+  }
+
+  function operation(doc, path, method, op) {
+    var details = el("div", "details");
+    if (op.description) details.appendChild(el("p", "", op.description));
+    if (op.security && op.security.length) details.appendChild(el("p", "muted", "Requires a bearer access token."));
+
+    var parameters = (op.parameters || []).map(function (parameter) { return resolve(doc, parameter); });
+    if (parameters.length) {
+      details.appendChild(el("h4", "", "Parameters"));
+      details.appendChild(el("table", "", parameters.map(function (parameter) {
+        return el("tr", "", [
+          el("td", "name", parameter.name + (parameter.required ? " *" : "")),
+          el("td", "type", parameter.in + ", " + typeOf(doc, parameter.schema || {})),
+          el("td", "", parameter.description || "")
+        ]);
+      })));
+    }
+
+    var body = op.requestBody && resolve(doc, op.requestBody);
+    if (body && jsonSchema(body.content)) {
+      details.appendChild(el("h4", "", "Request body"));
+      details.appendChild(schemaTable(doc, jsonSchema(body.content)));
+    }
+
+    details.appendChild(el("h4", "", "Responses"));
+    Object.keys(op.responses || {}).forEach(function (status) {
+      var response = resolve(doc, op.responses[status]);
+      var schema = jsonSchema(response.content);
+      details.appendChild(el("div", "response", [
+        el("span", "status status-" + status.charAt(0), status),
+        el("span", "", " " + (response.description || "")),
+        schema && status.charAt(0) === "2" ? schemaTable(doc, schema) : null
+      ]));
+    });
+
+    var summary = el("summary", "", [
+      el("span", "method method-" + method, method.toUpperCase()),
+      el("code", "path", path),
+      el("span", "summary", op.summary || op.operationId || ""),
+      op.deprecated ? el("span", "deprecated", "deprecated") : null
+    ]);
+    return el("details", "operation" + (op.deprecated ? " is-deprecated" : ""), [summary, details]);
+  }
+
+  function render(doc) {
+    var info = doc.info || {};
+    root.textContent = "";
+    root.appendChild(el("h1", "", (info.title || "API") + (info.version ? " " + info.version : "")));
+    if (info.description) root.appendChild(el("p", "", info.description));
+    root.appendChild(el("p", "muted", [el("a", "", "Download the OpenAPI document")]));
+    root.lastChild.firstChild.href = specURL;
+
+    var methods = ["get", "post", "put", "patch", "delete"];
+    var tags = (doc.tags || []).slice();
+    var sections = {};
+    tags.forEach(function (tag) { sections[tag.name] = []; });
+    // The deprecated aliases come after the current operations
+    [false, true].forEach(function (deprecated) {
+      Object.keys(doc.paths || {}).forEach(function (path) {
+        methods.forEach(function (method) {
+          var op = doc.paths[path][method];
+          if (!op || !op.deprecated !== !deprecated) return;
+          var tag = (op.tags && op.tags[0]) || "other";
+          if (!sections[tag]) {
+            sections[tag] = [];
+            tags.push({ name: tag });
+          }
+          sections[tag].push(operation(doc, path, method, op));
+        });
+      });
+    });
+    tags.forEach(function (tag) {
+      if (!sections[tag.name].length) return;
+      root.appendChild(el("section", "", [
+        el("h2", "", tag.name),
+        tag.description ? el("p", "muted", tag.description) : null
+      ].concat(sections[tag.name])));
+    });
+  }
+
+  fetch(specURL, { headers: { Accept: "application/json" } })
+    .then(function (resp) {
+      if (!resp.ok) throw new Error(specURL + " returned " + resp.status);
+      return resp.json();
+    })
+    .then(render)
+    .catch(function (err) {
+      root.textContent = "Failed to load the API description: " + err.message;
+    });
+})();
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpecPath and DocsPath are where the document and its rendering are served
+const (
+	SpecPath = "/openapi.json"
+	DocsPath = "/docs"
+)
+
+//go:embed docs.html
+var docsPage string
+
+// docsScript renders the document in the page, it is inlined so the page needs
+// nothing but the document
+//
+//go:embed docs.js
+var docsScript string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsPage))
+
+// Handler serves doc as JSON. The document is encoded once, it does not change
+// while the application runs.
+func Handler(doc *Document) gin.HandlerFunc {
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("openapi: failed to encode document: " + err.Error())
+	}
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json", body)
+	}
+}
+
+// DocsHandler serves a self-contained page rendering the document at specURL
+func DocsHandler(specURL string) gin.HandlerFunc {
+	var page bytes.Buffer
+	data := struct {
+		SpecURL string
+		Script  template.JS
+	}{specURL, template.JS(docsScript)}
+	if err := docsTemplate.Execute(&page, data); err != nil {
+		panic("openapi: failed to render docs page: " + err.Error())
+	}
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+	}
+}
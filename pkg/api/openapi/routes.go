@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Undocumented returns the registered routes missing from doc, as "METHOD path"
+// in gin syntax. Routes that are not part of the API, such as the document
+// itself or the metrics endpoint, can be passed in ignore by path.
+func Undocumented(doc *Document, routes gin.RoutesInfo, ignore ...string) []string {
+	skip := map[string]bool{SpecPath: true, DocsPath: true}
+	for _, path := range ignore {
+		skip[path] = true
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if skip[route.Path] {
+			continue
+		}
+		item, ok := doc.Paths[PathOf(route.Path)]
+		if !ok || item.Operation(route.Method) == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// PathOf converts a gin route path to an OpenAPI path template, e.g.
+// /organization/:organization_id to /organization/{organization_id}
+func PathOf(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
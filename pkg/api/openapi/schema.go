@@ -0,0 +1,149 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"Go-api/pkg/api/dto"
+)
+
+// passwordPattern requires a letter and a digit in either order, like the
+// password rule of pkg/api/dto
+const passwordPattern = `\p{L}[\s\S]*\p{Nd}|\p{Nd}[\s\S]*\p{L}`
+
+// schemas turns Go types into component schemas, named after the Go type
+type schemas map[string]*Schema
+
+// ref registers the schema of the struct type of v, or of what v points to, and
+// returns a reference to it
+func (s schemas) ref(v any) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return s.of(t)
+}
+
+// timeType is encoded as an RFC 3339 string rather than as a struct
+var timeType = reflect.TypeOf(time.Time{})
+
+func (s schemas) of(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		// A nil pointer is encoded as, and decoded from, null
+		schema := s.of(t.Elem())
+		if name, ok := schema.Type.(string); ok {
+			schema.Type = []string{name, "null"}
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := s[name]; !ok {
+			// Register first so recursive types terminate
+			s[name] = nil
+			s[name] = s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// object describes the exported fields of a struct. Request fields are required
+// when their binding tag says so; response fields when they are never omitted.
+func (s schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.of(field.Type)
+		required := false
+		if binding, ok := field.Tag.Lookup("binding"); ok {
+			required = applyBinding(property, binding)
+		} else {
+			required = field.Type.Kind() != reflect.Pointer && !strings.Contains(options, "omitempty")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// applyBinding adds the constraints of a validator binding tag to property and
+// reports whether the field is required
+func applyBinding(property *Schema, binding string) (required bool) {
+	for _, rule := range strings.Split(binding, ",") {
+		rule, param, _ := strings.Cut(rule, "=")
+		switch rule {
+		case "required":
+			required = true
+		case "email":
+			property.Format = "email"
+		case "notblank":
+			property.MinLength = intPtr(1)
+			property.Pattern = `\S`
+			property.rule(rule, "minLength", "pattern")
+		case "max":
+			if n, err := strconv.Atoi(param); err == nil {
+				property.MaxLength = intPtr(n)
+			}
+		case "min":
+			if n, err := strconv.Atoi(param); err == nil {
+				property.MinLength = intPtr(n)
+			}
+		case "password":
+			property.MinLength = intPtr(dto.MinPasswordLength)
+			property.MaxLength = intPtr(dto.MaxPasswordLength)
+			property.Pattern = passwordPattern
+			property.Description = "At least one letter and one digit"
+			property.rule(rule, "minLength", "maxLength", "pattern")
+		case "orgname":
+			property.Pattern = dto.OrganizationNamePattern
+			property.rule(rule, "pattern")
+		}
+	}
+	return required
+}
+
+// rule records that the keywords were set by a dto binding rule
+func (s *Schema) rule(rule string, keywords ...string) {
+	if s.rules == nil {
+		s.rules = map[string]string{}
+	}
+	for _, keyword := range keywords {
+		s.rules[keyword] = rule
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,526 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
+)
+
+const (
+	jsonType = "application/json"
+	// apiVersion prefixes the API routes, see pkg/api/routes
+	apiVersion = "/v1"
+	// bearerAuth names the security scheme of the access token
+	bearerAuth = "bearerAuth"
+)
+
+// Build returns the OpenAPI document of every route the application serves. A
+// new route must be described here too, see Undocumented.
+func Build() *Document {
+	s := schemas{}
+	b := &builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       "Go-api",
+				Version:     "1.0.0",
+				Description: "Users, organizations and their members. Errors are RFC 7807 problem documents whose codes are documented at " + strings.TrimSuffix(problem.TypeBase, "#") + ".",
+			},
+			Tags: []Tag{
+				{Name: "user", Description: "Sign-up, sign-in and tokens"},
+				{Name: "organization", Description: "Organizations and their members"},
+				{Name: "admin", Description: "Platform administration, reserved to platform admins"},
+				{Name: "health", Description: "Probes and the health report"},
+			},
+			Paths: map[string]*PathItem{},
+		},
+		schemas: s,
+	}
+
+	orgID := Parameter{
+		Name:        "organization_id",
+		In:          "path",
+		Description: "ID of the organization, malformed IDs are rejected with invalid_organization_id",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+	ifMatch := Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "ETag of the organization from the last read, required for writes",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+	ifNoneMatch := Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag the client already holds, answered with 304 when still current",
+		Schema:      &Schema{Type: "string"},
+	}
+	etag := map[string]*Header{"ETag": {Description: "Current version of the organization", Schema: &Schema{Type: "string"}}}
+
+	// User routes
+	b.api("POST", "/user/signup", &Operation{
+		OperationID: "signUp",
+		Summary:     "Register a user",
+		Tags:        []string{"user"},
+		RequestBody: b.body(&dto.SignUpRequest{}),
+		Responses: responses(
+			b.ok(http.StatusCreated, "User created", &dto.MessageResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity),
+		),
+	})
+	b.api("POST", "/user/signin", &Operation{
+		OperationID: "signIn",
+		Summary:     "Sign in with email and password",
+		Description: "Returns an access token for the Authorization header and a refresh token for POST /v1/user/refresh. Suspended and deactivated users are refused with user_suspended and user_deactivated.",
+		Tags:        []string{"user"},
+		RequestBody: b.body(&dto.SignInRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "Signed in", &dto.TokenResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity),
+		),
+	})
+	b.api("POST", "/user/refresh", &Operation{
+		OperationID: "refreshToken",
+		Summary:     "Exchange a refresh token for a new access token",
+		Description: "Impersonation tokens cannot be refreshed, nor tokens of users who are no longer active.",
+		Tags:        []string{"user"},
+		RequestBody: b.body(&dto.RefreshRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "New access token", &dto.TokenResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity),
+		),
+	})
+
+	// Organization routes, all authenticated. Every authenticated route answers 403
+	// user_suspended or user_deactivated once the account is no longer active.
+	b.api("POST", "/organization/", &Operation{
+		OperationID: "createOrganization",
+		Summary:     "Create an organization",
+		Description: "The current user becomes its first member and administrator.",
+		Tags:        []string{"organization"},
+		RequestBody: b.body(&dto.OrganizationRequest{}),
+		Responses: responses(
+			b.ok(http.StatusCreated, "Organization created", &dto.OrganizationCreatedResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity),
+		),
+		Security: bearer(),
+	})
+	b.api("GET", "/organization/", &Operation{
+		OperationID: "listOrganizations",
+		Summary:     "List the organizations the current user is a member of",
+		Tags:        []string{"organization"},
+		Responses: responses(
+			b.ok(http.StatusOK, "The organizations, oldest first", &dto.OrganizationListResponse{}, nil),
+			problems(http.StatusUnauthorized, http.StatusForbidden),
+		),
+		Security: bearer(),
+	})
+	b.api("GET", "/organization/{organization_id}", &Operation{
+		OperationID: "getOrganization",
+		Summary:     "Get an organization",
+		Tags:        []string{"organization"},
+		Parameters:  []Parameter{orgID, ifNoneMatch},
+		Responses: responses(
+			b.ok(http.StatusOK, "The organization", &dto.OrganizationResponse{}, etag),
+			map[string]*Response{"304": {Description: "The client's copy is current", Headers: etag}},
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound),
+		),
+		Security: bearer(),
+	})
+	b.api("PUT", "/organization/{organization_id}", &Operation{
+		OperationID: "updateOrganization",
+		Summary:     "Replace the name and description of an organization",
+		Description: "Administrators only.",
+		Tags:        []string{"organization"},
+		Parameters:  []Parameter{orgID, ifMatch},
+		RequestBody: b.body(&dto.OrganizationRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "The updated organization", &dto.OrganizationResponse{}, etag),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+				http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired),
+		),
+		Security: bearer(),
+	})
+	b.api("PATCH", "/organization/{organization_id}", &Operation{
+		OperationID: "patchOrganization",
+		Summary:     "Change some fields of an organization",
+		Description: "Administrators only. Fields left out are not changed.",
+		Tags:        []string{"organization"},
+		Parameters:  []Parameter{orgID, ifMatch},
+		RequestBody: b.body(&dto.PatchOrganizationRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "The updated organization", &dto.OrganizationResponse{}, etag),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+				http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired),
+		),
+		Security: bearer(),
+	})
+	b.api("DELETE", "/organization/{organization_id}", &Operation{
+		OperationID: "deleteOrganization",
+		Summary:     "Delete an organization",
+		Description: "Administrators only.",
+		Tags:        []string{"organization"},
+		Parameters:  []Parameter{orgID, ifMatch},
+		Responses: responses(
+			b.ok(http.StatusOK, "Organization deleted", &dto.MessageResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+				http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/organization/{organization_id}/invite", &Operation{
+		OperationID: "inviteMember",
+		Summary:     "Add a registered user to an organization",
+		Description: "Administrators only.",
+		Tags:        []string{"organization"},
+		Parameters:  []Parameter{orgID},
+		RequestBody: b.body(&dto.InviteRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "User invited", &dto.MessageResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+				http.StatusConflict, http.StatusUnprocessableEntity),
+		),
+		Security: bearer(),
+	})
+	b.api("DELETE", "/organization/{organization_id}/members/{email}", &Operation{
+		OperationID: "removeMember",
+		Summary:     "Remove a member from an organization",
+		Description: "Administrators may remove anyone, other members only themselves. The last administrator cannot be removed.",
+		Tags:        []string{"organization"},
+		Parameters: []Parameter{orgID, {
+			Name:        "email",
+			In:          "path",
+			Description: "Email of the member",
+			Required:    true,
+			Schema:      &Schema{Type: "string", Format: "email"},
+		}},
+		Responses: responses(
+			b.ok(http.StatusOK, "Member removed", &dto.MessageResponse{}, nil),
+			problems(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict),
+		),
+		Security: bearer(),
+	})
+
+	// Admin routes, for platform admins signed in as themselves
+	userID := Parameter{
+		Name:        "user_id",
+		In:          "path",
+		Description: "ID of the user, malformed IDs are rejected with invalid_user_id",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+	search := Parameter{
+		Name:        "q",
+		In:          "query",
+		Description: "Only return the matches of this text, ignoring case",
+		Schema:      &Schema{Type: "string"},
+	}
+	adminProblems := []int{http.StatusUnauthorized, http.StatusForbidden}
+	b.api("GET", "/admin/users", &Operation{
+		OperationID: "adminListUsers",
+		Summary:     "List every user",
+		Description: "q matches the name or email.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{search},
+		Responses: responses(
+			b.ok(http.StatusOK, "The users, oldest first", &dto.UserListResponse{}, nil),
+			problems(adminProblems...),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/admin/users/{user_id}/suspend", &Operation{
+		OperationID: "adminSuspendUser",
+		Summary:     "Suspend a user",
+		Description: "Suspended users cannot sign in, and their tokens stop working at once. Platform admins cannot suspend themselves.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{userID},
+		Responses: responses(
+			b.ok(http.StatusOK, "The suspended user", &dto.AdminUserResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict)...),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/admin/users/{user_id}/deactivate", &Operation{
+		OperationID: "adminDeactivateUser",
+		Summary:     "Deactivate the account of someone who left",
+		Description: "Like a suspension, the user keeps their data and memberships but cannot sign in, and their tokens stop working at once. Platform admins cannot deactivate themselves.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{userID},
+		Responses: responses(
+			b.ok(http.StatusOK, "The deactivated user", &dto.AdminUserResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict)...),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/admin/users/{user_id}/reinstate", &Operation{
+		OperationID: "adminReinstateUser",
+		Summary:     "Make a suspended or deactivated user active again",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{userID},
+		Responses: responses(
+			b.ok(http.StatusOK, "The reinstated user", &dto.AdminUserResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound)...),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/admin/users/{user_id}/impersonate", &Operation{
+		OperationID: "adminImpersonateUser",
+		Summary:     "Get an access token that acts as a user",
+		Description: "The token expires after auth.impersonation_token_ttl and cannot be refreshed nor used on the admin routes. " +
+			"The reason and every request made with the token are logged. Platform admins and users who are not active cannot be impersonated.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{userID},
+		RequestBody: b.body(&dto.ImpersonateRequest{}),
+		Responses: responses(
+			b.ok(http.StatusOK, "The impersonation token", &dto.ImpersonationResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity)...),
+		),
+		Security: bearer(),
+	})
+	b.api("GET", "/admin/organizations", &Operation{
+		OperationID: "adminListOrganizations",
+		Summary:     "List every organization",
+		Description: "q matches the name, description or a member's email.",
+		Tags:        []string{"admin"},
+		Parameters: []Parameter{search, {
+			Name:        "deleted",
+			In:          "query",
+			Description: "List the force-deleted organizations, which can be restored, instead",
+			Schema:      &Schema{Type: "string", Enum: []any{"true", "false"}},
+		}},
+		Responses: responses(
+			b.ok(http.StatusOK, "The organizations, oldest first", &dto.AdminOrganizationListResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest)...),
+		),
+		Security: bearer(),
+	})
+	b.api("DELETE", "/admin/organizations/{organization_id}", &Operation{
+		OperationID: "adminForceDeleteOrganization",
+		Summary:     "Delete an organization at any version",
+		Description: "A copy is kept, see the restore operation.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{orgID},
+		Responses: responses(
+			b.ok(http.StatusOK, "Organization deleted", &dto.MessageResponse{}, nil),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound)...),
+		),
+		Security: bearer(),
+	})
+	b.api("POST", "/admin/organizations/{organization_id}/restore", &Operation{
+		OperationID: "adminRestoreOrganization",
+		Summary:     "Restore a force-deleted organization",
+		Description: "Fails with organization_name_exists if its name was taken in the meantime.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{orgID},
+		Responses: responses(
+			b.ok(http.StatusOK, "The restored organization", &dto.AdminOrganizationResponse{}, etag),
+			problems(append(adminProblems, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict)...),
+		),
+		Security: bearer(),
+	})
+
+	// Health routes
+	s["HealthStatus"] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"status": {Type: "string"}, "checks": {Type: "object"}},
+		Required:   []string{"status"},
+	}
+	s["HealthReport"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":         {Type: "string", Enum: []any{"up", "down", "shutting down"}},
+			"uptime_seconds": {Type: "integer"},
+			"checks": {Type: "object", AdditionalProperties: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"status":     {Type: "string", Enum: []any{"up", "down"}},
+					"critical":   {Type: "boolean"},
+					"latency_ms": {Type: "number"},
+					"error":      {Type: "string", Enum: []any{"failed", "timed out"}, Description: "Why the check is down, the server logs the details"},
+				},
+				Required: []string{"status", "critical", "latency_ms"},
+			}},
+		},
+		Required: []string{"status", "uptime_seconds", "checks"},
+	}
+	healthStatus := &Schema{Ref: "#/components/schemas/HealthStatus"}
+	b.add("GET", "/healthz", &Operation{
+		OperationID: "liveness",
+		Summary:     "Liveness probe",
+		Tags:        []string{"health"},
+		Responses:   map[string]*Response{"200": jsonResponse("The process is up", healthStatus, nil)},
+	})
+	b.add("GET", "/readyz", &Operation{
+		OperationID: "readiness",
+		Summary:     "Readiness probe",
+		Tags:        []string{"health"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("Ready for traffic", healthStatus, nil),
+			"503": jsonResponse("A critical dependency is down or the server is shutting down", healthStatus, nil),
+		},
+	})
+	healthReport := &Schema{Ref: "#/components/schemas/HealthReport"}
+	b.add("GET", "/health", &Operation{
+		OperationID: "healthReport",
+		Summary:     "Status and latency of every dependency",
+		Tags:        []string{"health"},
+		Responses: responses(
+			map[string]*Response{
+				"200": jsonResponse("Every critical dependency is up", healthReport, nil),
+				"503": jsonResponse("A critical dependency is down or the server is shutting down", healthReport, nil),
+			},
+			problems(http.StatusUnauthorized, http.StatusForbidden),
+		),
+		Security: bearer(),
+	})
+
+	b.doc.Components = Components{
+		Schemas:   s,
+		Responses: problemResponses(s),
+		SecuritySchemes: map[string]*SecurityScheme{
+			bearerAuth: {
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+				Description:  "Access token from POST /v1/user/signin or POST /v1/user/refresh",
+			},
+		},
+	}
+	return b.doc
+}
+
+type builder struct {
+	doc     *Document
+	schemas schemas
+}
+
+func (b *builder) add(method, path string, op *Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	// Bodies that are not JSON are rejected when requests are validated
+	if op.RequestBody != nil {
+		op.Responses = responses(op.Responses, problems(http.StatusUnsupportedMediaType))
+	}
+	item.set(method, op)
+}
+
+// api adds an operation of the versioned API, and its deprecated alias at the
+// unversioned path
+func (b *builder) api(method, path string, op *Operation) {
+	// Every route group has a rate limit, see rate_limits.groups
+	op.Responses = responses(op.Responses, problems(http.StatusTooManyRequests))
+	b.add(method, apiVersion+path, op)
+
+	alias := *op
+	alias.OperationID += "Unversioned"
+	alias.Description = strings.TrimSpace("Deprecated alias of " + method + " " + apiVersion + path + ", responses carry the Deprecation and Sunset headers. " + op.Description)
+	alias.Deprecated = true
+	b.add(method, path, &alias)
+}
+
+// body is a required JSON request body of the type of v
+func (b *builder) body(v any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{jsonType: {Schema: b.schemas.ref(v)}},
+	}
+}
+
+// ok is the success response of an operation, with a JSON body of the type of v
+func (b *builder) ok(status int, description string, v any, headers map[string]*Header) map[string]*Response {
+	return map[string]*Response{strconv.Itoa(status): jsonResponse(description, b.schemas.ref(v), headers)}
+}
+
+func jsonResponse(description string, schema *Schema, headers map[string]*Header) *Response {
+	return &Response{
+		Description: description,
+		Headers:     headers,
+		Content:     map[string]*MediaType{jsonType: {Schema: schema}},
+	}
+}
+
+func bearer() []map[string][]string {
+	return []map[string][]string{{bearerAuth: {}}}
+}
+
+// responses merges the response maps of an operation
+func responses(sets ...map[string]*Response) map[string]*Response {
+	merged := map[string]*Response{}
+	for _, set := range sets {
+		for status, response := range set {
+			merged[status] = response
+		}
+	}
+	return merged
+}
+
+// problemCodes lists the error codes each status can carry, see docs/errors.md
+var problemCodes = map[int][]string{
+	http.StatusBadRequest:           {problem.CodeInvalidRequest, "invalid_user_id", "invalid_organization_id"},
+	http.StatusUnauthorized:         {problem.CodeMissingToken, problem.CodeInvalidToken, problem.CodeTokenExpired, problem.CodeInvalidRefreshToken, "invalid_credentials", "unknown_user"},
+	http.StatusForbidden:            {"not_a_member", "insufficient_access_level", "user_suspended", "user_deactivated", "platform_admin_required", "impersonation_not_allowed"},
+	http.StatusNotFound:             {"organization_not_found", "user_not_found", "member_not_found"},
+	http.StatusConflict:             {"email_exists", "organization_name_exists", "member_exists", "last_administrator", "cannot_suspend_self"},
+	http.StatusPreconditionFailed:   {"version_mismatch"},
+	http.StatusUnsupportedMediaType: {problem.CodeUnsupportedMediaType},
+	http.StatusUnprocessableEntity:  {problem.CodeValidationFailed},
+	http.StatusPreconditionRequired: {problem.CodePreconditionRequired},
+	http.StatusTooManyRequests:      {problem.CodeRateLimited},
+	http.StatusInternalServerError:  {problem.CodeInternalError},
+	http.StatusServiceUnavailable:   {problem.CodeRequestTimeout},
+	http.StatusGatewayTimeout:       {problem.CodeDatabaseTimeout},
+}
+
+// commonProblems can be returned by any operation
+var commonProblems = []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// problems references the problem responses of the given statuses, plus the ones
+// every operation can return
+func problems(statuses ...int) map[string]*Response {
+	refs := map[string]*Response{}
+	for _, status := range append(statuses, commonProblems...) {
+		refs[strconv.Itoa(status)] = &Response{Ref: "#/components/responses/" + problemName(status)}
+	}
+	return refs
+}
+
+// problemResponses are the shared problem+json responses, one per status, each
+// listing the codes it can carry
+func problemResponses(s schemas) map[string]*Response {
+	base := s.ref(&problem.Problem{})
+	shared := map[string]*Response{}
+	for status, codes := range problemCodes {
+		enum := make([]any, len(codes))
+		for i, code := range codes {
+			enum[i] = code
+		}
+		schema := &Schema{AllOf: []*Schema{base, {
+			Type:       "object",
+			Properties: map[string]*Schema{"code": {Type: "string", Enum: enum}},
+		}}}
+		shared[problemName(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{problem.ContentType: {Schema: schema}},
+		}
+	}
+	shared[problemName(http.StatusTooManyRequests)].Headers = map[string]*Header{
+		"Retry-After":         {Description: "Seconds until the request can be sent again", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Limit":     {Description: "Requests that can be made at once", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Remaining": {Description: "Requests left right now, 0 when refused", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Reset":     {Description: "Seconds until the limit is fully restored", Schema: &Schema{Type: "integer"}},
+	}
+	return shared
+}
+
+// problemName names the shared response of status, e.g. Problem409
+func problemName(status int) string {
+	return "Problem" + strconv.Itoa(status)
+}
@@ -0,0 +1,241 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"Go-api/pkg/api/dto"
+)
+
+// Violation is one way a value breaks a schema
+type Violation struct {
+	// Path locates the value, e.g. name or members[0].email, empty for the root
+	Path string
+	// Rule is the failed rule, named like the binding rules of pkg/api/dto:
+	// required, email, max, min, orgname, password, type, enum, pattern or unknown
+	Rule    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + " " + v.Message
+}
+
+// Find returns the operation serving method on a gin route path, nil if the
+// document does not describe it
+func (d *Document) Find(method, ginPath string) *Operation {
+	item, ok := d.Paths[PathOf(ginPath)]
+	if !ok {
+		return nil
+	}
+	return item.Operation(method)
+}
+
+// Response returns the response described for status, following references,
+// or nil if there is none
+func (d *Document) Response(op *Operation, status int) *Response {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if response, ok = op.Responses["default"]; !ok {
+			return nil
+		}
+	}
+	if name, ok := strings.CutPrefix(response.Ref, "#/components/responses/"); ok {
+		return d.Components.Responses[name]
+	}
+	return response
+}
+
+// Validate checks a value decoded from JSON against schema and returns every
+// violation, at most one per path and rule
+func (d *Document) Validate(schema *Schema, value any) []Violation {
+	v := &validation{doc: d, seen: map[string]bool{}}
+	v.check(schema, "", value)
+	return v.violations
+}
+
+type validation struct {
+	doc        *Document
+	violations []Violation
+	seen       map[string]bool
+}
+
+func (v *validation) add(path, rule, message string) {
+	key := path + "\x00" + rule
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.violations = append(v.violations, Violation{Path: path, Rule: rule, Message: message})
+}
+
+// resolve follows a reference to a component schema
+func (v *validation) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		schema = v.doc.Components.Schemas[name]
+	}
+	return schema
+}
+
+func (v *validation) check(schema *Schema, path string, value any) {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return
+	}
+	for _, part := range schema.AllOf {
+		v.check(part, path, value)
+	}
+	if schema.Type != nil && !hasType(schema.Type, value) {
+		v.add(path, "type", fmt.Sprintf("must be of type %v", schema.Type))
+		return
+	}
+	if len(schema.Enum) > 0 && !contains(schema.Enum, value) {
+		v.add(path, "enum", fmt.Sprintf("must be one of %v", schema.Enum))
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.checkString(schema, path, value)
+	case []any:
+		for i, item := range value {
+			v.check(schema.Items, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				v.add(join(path, name), "required", dto.RuleMessage("required", ""))
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property := value[name]
+			if propertySchema, ok := schema.Properties[name]; ok {
+				v.check(propertySchema, join(path, name), property)
+				continue
+			}
+			switch additional := schema.AdditionalProperties.(type) {
+			case *Schema:
+				v.check(additional, join(path, name), property)
+			case bool:
+				if !additional {
+					v.add(join(path, name), "unknown", "is not a known field")
+				}
+			}
+		}
+	}
+}
+
+func (v *validation) checkString(schema *Schema, path, value string) {
+	// Lengths count characters, like the max and min binding rules
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(schema, "minLength", "min", path, *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(schema, "maxLength", "max", path, *schema.MaxLength)
+	}
+	if schema.Pattern != "" && !compile(schema.Pattern).MatchString(value) {
+		v.fail(schema, "pattern", "pattern", path, 0)
+	}
+	if schema.Format == "email" && !validEmail(value) {
+		v.add(path, "email", dto.RuleMessage("email", ""))
+	}
+}
+
+// fail reports a failed keyword under the binding rule that set it, if any
+func (v *validation) fail(schema *Schema, keyword, rule, path string, limit int) {
+	if bound, ok := schema.rules[keyword]; ok {
+		rule = bound
+	}
+	if rule == "pattern" {
+		v.add(path, rule, "has an invalid format")
+		return
+	}
+	v.add(path, rule, dto.RuleMessage(rule, strconv.Itoa(limit)))
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// hasType reports whether value, as decoded by encoding/json, is of the JSON
+// Schema type t, a name or a list of names
+func hasType(t any, value any) bool {
+	if names, ok := t.([]string); ok {
+		for _, name := range names {
+			if hasType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func contains(values []any, value any) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validEmail accepts a bare address such as ada@example.com, without a display
+// name or angle brackets
+func validEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value && address.Name == ""
+}
+
+// patterns caches the compiled schema patterns
+var patterns sync.Map
+
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
@@ -0,0 +1,106 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testDocument describes an organization with nested members and tags
+func testDocument() *Document {
+	name := &Schema{Type: "string", MinLength: intPtr(2), MaxLength: intPtr(4), Pattern: `^[a-z]+$`}
+	return &Document{Components: Components{Schemas: map[string]*Schema{
+		"Member": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"email": {Type: "string", Format: "email"},
+				"level": {Type: "integer", Enum: []any{0.0, 1.0, 2.0}},
+			},
+			Required: []string{"email"},
+		},
+		"Organization": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"name":        name,
+				"description": {Type: []string{"string", "null"}},
+				"members":     {Type: "array", Items: &Schema{Ref: "#/components/schemas/Member"}},
+				"tags":        {Type: "array", Items: &Schema{Type: "array", Items: &Schema{Type: "string", MaxLength: intPtr(3)}}},
+				"labels":      {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			},
+			Required:             []string{"name"},
+			AdditionalProperties: false,
+		},
+	}}}
+}
+
+func TestValidate(t *testing.T) {
+	organization := &Schema{Ref: "#/components/schemas/Organization"}
+	tests := []struct {
+		name  string
+		value string
+		want  []Violation
+	}{
+		{"valid", `{"name": "acme", "description": null, "members": [{"email": "ada@example.com", "level": 1}], "tags": [["a", "b"], []], "labels": {"tier": "gold"}}`, nil},
+		{"root type", `[]`, []Violation{{Path: "", Rule: "type"}}},
+		{"required", `{}`, []Violation{{Path: "name", Rule: "required"}}},
+		{"property type", `{"name": 1}`, []Violation{{Path: "name", Rule: "type"}}},
+		{"type list", `{"name": "acme", "description": 1}`, []Violation{{Path: "description", Rule: "type"}}},
+		{"unknown field", `{"name": "acme", "owner": "ada"}`, []Violation{{Path: "owner", Rule: "unknown"}}},
+		{"min length", `{"name": "a"}`, []Violation{{Path: "name", Rule: "min"}}},
+		{"max length", `{"name": "acmes"}`, []Violation{{Path: "name", Rule: "max"}}},
+		{"length counts characters", `{"name": "ééé"}`, []Violation{{Path: "name", Rule: "pattern"}}},
+		{"pattern", `{"name": "AC"}`, []Violation{{Path: "name", Rule: "pattern"}}},
+		{"several rules", `{"name": "ACMES"}`, []Violation{{Path: "name", Rule: "max"}, {Path: "name", Rule: "pattern"}}},
+		{"ref required", `{"name": "acme", "members": [{"level": 1}]}`, []Violation{{Path: "members[0].email", Rule: "required"}}},
+		{"ref format", `{"name": "acme", "members": [{"email": "Ada <ada@example.com>"}]}`, []Violation{{Path: "members[0].email", Rule: "email"}}},
+		{"enum", `{"name": "acme", "members": [{"email": "ada@example.com"}, {"email": "bob@example.com", "level": 3}]}`, []Violation{{Path: "members[1].level", Rule: "enum"}}},
+		{"integer", `{"name": "acme", "members": [{"email": "ada@example.com", "level": 1.5}]}`, []Violation{{Path: "members[0].level", Rule: "type"}}},
+		{"nested arrays", `{"name": "acme", "tags": [["a"], ["long", 1]]}`, []Violation{{Path: "tags[1][0]", Rule: "max"}, {Path: "tags[1][1]", Rule: "type"}}},
+		{"additional properties", `{"name": "acme", "labels": {"tier": 1}}`, []Violation{{Path: "labels.tier", Rule: "type"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			var got []Violation
+			for _, violation := range testDocument().Validate(organization, value) {
+				if violation.Message == "" {
+					t.Errorf("%s %s has no message", violation.Path, violation.Rule)
+				}
+				got = append(got, Violation{Path: violation.Path, Rule: violation.Rule})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%s) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateBindingRules checks that keywords set by a binding tag are
+// reported under the rule of the tag, like dto.Bind does
+func TestValidateBindingRules(t *testing.T) {
+	tests := []struct {
+		binding string
+		value   string
+		want    Violation
+	}{
+		{"orgname", "-acme", Violation{Rule: "orgname", Message: "must be 2 to 64 letters, digits, spaces, dots, underscores or hyphens, starting with a letter or digit"}},
+		{"password", "short1", Violation{Rule: "password", Message: "must be 8 to 72 characters long and contain a letter and a digit"}},
+		{"password", "longenough", Violation{Rule: "password", Message: "must be 8 to 72 characters long and contain a letter and a digit"}},
+		{"notblank", " ", Violation{Rule: "notblank", Message: "must not be blank"}},
+		{"max=3", "abcd", Violation{Rule: "max", Message: "must be at most 3 characters long"}},
+		{"email", "ada", Violation{Rule: "email", Message: "must be a valid email address"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.binding, func(t *testing.T) {
+			schema := &Schema{Type: "string"}
+			applyBinding(schema, tt.binding)
+			got := (&Document{}).Validate(schema, tt.value)
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("Validate(%q) with %s = %+v, want %+v", tt.value, tt.binding, got, tt.want)
+			}
+		})
+	}
+}
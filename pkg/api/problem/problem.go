@@ -0,0 +1,172 @@
+// Package problem writes errors as RFC 7807 application/problem+json documents
+// and maps domain and storage errors to them. Every problem carries a stable
+// code, documented in docs/errors.md, that clients can branch on.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"Go-api/pkg/database/repository"
+)
+
+// ContentType is the media type of problem documents
+const ContentType = "application/problem+json"
+
+// TypeBase prefixes the code to form the problem type URI, which points at the
+// code's documentation
+const TypeBase = "https://github.com/Saladin-99/Go-api/blob/main/docs/errors.md#"
+
+// StatusClientClosedRequest is logged when the client disconnected before a
+// response could be written
+const StatusClientClosedRequest = 499
+
+// Stable error codes not owned by a domain error
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeMissingToken         = "missing_token"
+	CodeInvalidToken         = "invalid_token"
+	CodeTokenExpired         = "token_expired"
+	CodeInvalidRefreshToken  = "invalid_refresh_token"
+	CodePreconditionRequired = "precondition_required"
+	CodeRouteNotFound        = "route_not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeRequestTimeout       = "request_timeout"
+	CodeDatabaseTimeout      = "database_timeout"
+	CodeInternalError        = "internal_error"
+	CodeRateLimited          = "rate_limited"
+)
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the stable machine-readable error code
+	Code string `json:"code"`
+	// Errors lists every invalid field of a validation_failed problem
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one invalid field of a request body
+type FieldError struct {
+	// Field is the JSON name of the field
+	Field string `json:"field"`
+	// Code is the failed rule, e.g. required, email or password
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// New returns the problem for code with the standard title of status
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   TypeBase + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// kindStatus maps the domain error kinds to HTTP status codes
+var kindStatus = []struct {
+	kind   error
+	status int
+}{
+	{repository.ErrNotFound, http.StatusNotFound},
+	{repository.ErrConflict, http.StatusConflict},
+	{repository.ErrInvalidID, http.StatusBadRequest},
+	{repository.ErrForbidden, http.StatusForbidden},
+	{repository.ErrUnauthenticated, http.StatusUnauthorized},
+	{repository.ErrPreconditionFailed, http.StatusPreconditionFailed},
+}
+
+// FromError maps err to a problem. requestCtx is the context of the request, whose
+// deadline tells a slow request apart from a slow database operation. Errors that
+// are not domain errors become a 500 with a generic detail, their text is never
+// shown to clients. ok is false when the client has gone away and nothing should
+// be written.
+func FromError(requestCtx context.Context, err error) (p *Problem, ok bool) {
+	requestErr := requestCtx.Err()
+	switch {
+	case errors.Is(requestErr, context.Canceled):
+		return nil, false
+	case errors.Is(requestErr, context.DeadlineExceeded):
+		return New(http.StatusServiceUnavailable, CodeRequestTimeout, "request timed out"), true
+	case errors.Is(err, repository.ErrTimeout):
+		return New(http.StatusGatewayTimeout, CodeDatabaseTimeout, "database operation timed out"), true
+	}
+
+	var domainErr *repository.DomainError
+	if errors.As(err, &domainErr) {
+		for _, ks := range kindStatus {
+			if errors.Is(domainErr, ks.kind) {
+				return New(ks.status, domainErr.Code, domainErr.Message), true
+			}
+		}
+	}
+	return New(http.StatusInternalServerError, CodeInternalError, "internal server error"), true
+}
+
+// Write aborts the request with p
+func Write(ctx *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = ctx.Request.URL.Path
+	}
+	ctx.Header("Content-Type", ContentType)
+	ctx.AbortWithStatusJSON(p.Status, p)
+}
+
+// Abort aborts the request with a problem for code
+func Abort(ctx *gin.Context, status int, code, detail string) {
+	Write(ctx, New(status, code, detail))
+}
+
+// Error aborts the request with the problem err maps to, see FromError
+func Error(ctx *gin.Context, err error) {
+	p, ok := FromError(ctx.Request.Context(), err)
+	if !ok {
+		ctx.AbortWithStatus(StatusClientClosedRequest)
+		return
+	}
+	Write(ctx, p)
+}
+
+// InvalidRequest aborts with a 400 for a request body that could not be bound.
+// The detail names the offending field without echoing decoder internals.
+func InvalidRequest(ctx *gin.Context, err error) {
+	Abort(ctx, http.StatusBadRequest, CodeInvalidRequest, bindingDetail(err))
+}
+
+func bindingDetail(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is not valid JSON"
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("field %s must be of type %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &validationErrs) && len(validationErrs) > 0:
+		fields := make([]string, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fields[i] = fieldErr.Field()
+		}
+		return "missing or invalid fields: " + strings.Join(fields, ", ")
+	}
+	return "request body is invalid"
+}
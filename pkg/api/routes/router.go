@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"Go-api/pkg/api/handlers"
+	"Go-api/pkg/api/middleware"
+	"Go-api/pkg/api/openapi"
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/config"
+	"Go-api/pkg/controllers"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/logging"
+	"Go-api/pkg/metrics"
+	"Go-api/pkg/ratelimit"
+	"Go-api/pkg/utils"
+)
+
+// HealthCheckTimeout bounds the dependency probes of the health endpoints
+const HealthCheckTimeout = 2 * time.Second
+
+// Dependencies is everything the router is built from. The repositories are
+// injected, so the production router can be served from any store, e.g. the
+// in-memory repositories in tests, without a database.
+type Dependencies struct {
+	// Config returns the running configuration. The settings that are reloaded
+	// without a restart, such as the CORS origins and the JWT secrets, are read
+	// from it on every request; the others when the router is built.
+	Config        func() *config.Config
+	Users         repository.UserRepository
+	Organizations repository.OrganizationRepository
+	// RateLimits keeps the rate limit buckets, in process when nil
+	RateLimits ratelimit.Store
+	// Health serves the probes, one without any check when nil
+	Health *handlers.HealthHandler
+	// Metrics is nil when metrics are disabled
+	Metrics *metrics.Metrics
+	// Logger is the root logger the package loggers are derived from, nothing is
+	// logged when nil
+	Logger *slog.Logger
+}
+
+// New builds the production router: the middleware stack, the health and
+// documentation endpoints and every API version with its authentication. The
+// engine is the http.Handler to serve, and its Routes are what the routes
+// tests check against the OpenAPI document.
+func New(deps Dependencies) *gin.Engine {
+	cfg := deps.Config()
+	if deps.Logger == nil {
+		deps.Logger = logging.Discard()
+	}
+	router := gin.New()
+	// Clients are told apart by IP, only trusted proxies may set it
+	router.SetTrustedProxies(cfg.Server.TrustedProxies)
+
+	// Initialize controllers
+	tokens := controllers.TokenConfig{
+		Keys:            jwtKeys(deps.Config),
+		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+
+		ImpersonationTokenTTL: cfg.Auth.ImpersonationTokenTTL,
+	}
+	controllerLogger := logging.For(deps.Logger, "controllers")
+	userController := controllers.NewUserController(controllerLogger, deps.Users, tokens, deps.Metrics)
+	orgController := controllers.NewOrganizationController(controllerLogger, deps.Organizations, deps.Users)
+	adminController := controllers.NewAdminController(controllerLogger, logging.Audit(deps.Logger), deps.Users, deps.Organizations, tokens)
+
+	authenticate := middleware.Authenticate(tokens.Keys, deps.Users, deps.Metrics)
+
+	httpLogger := logging.For(deps.Logger, "http")
+	router.Use(middleware.Recovery(httpLogger))
+	// Join the caller's trace from the traceparent header, before anything logs
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Logger(httpLogger))
+	if deps.Metrics != nil {
+		router.Use(middleware.Metrics(deps.Metrics))
+		router.GET(cfg.Metrics.Path, gin.WrapH(deps.Metrics.Handler()))
+	}
+	router.Use(middleware.CORS(func() []string { return deps.Config().Server.CORSOrigins }))
+	router.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+
+	// Unknown routes get problem documents like every other error
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(ctx *gin.Context) {
+		problem.Abort(ctx, http.StatusNotFound, problem.CodeRouteNotFound, "no route matches "+ctx.Request.URL.Path)
+	})
+	router.NoMethod(func(ctx *gin.Context) {
+		problem.Abort(ctx, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, ctx.Request.Method+" is not allowed on "+ctx.Request.URL.Path)
+	})
+
+	// Probes for the orchestrator, and a detailed report for operators
+	health := deps.Health
+	if health == nil {
+		health = handlers.NewHealthHandler(logging.For(deps.Logger, "health"), HealthCheckTimeout)
+	}
+	router.GET("/healthz", health.Liveness)
+	router.GET("/readyz", health.Readiness)
+	router.GET("/health", authenticate, health.Report)
+
+	// The API description and its human readable rendering
+	spec := openapi.Build()
+	if !cfg.API.UnversionedRoutes {
+		spec.DropDeprecated()
+	}
+	router.GET(openapi.SpecPath, openapi.Handler(spec))
+	router.GET(openapi.DocsPath, openapi.DocsHandler(openapi.SpecPath))
+
+	// Hold the API routes to the document
+	var contract []gin.HandlerFunc
+	if cfg.OpenAPI.ValidateRequests || cfg.OpenAPI.ValidateResponses {
+		contract = append(contract, middleware.Contract(spec, httpLogger, cfg.OpenAPI.ValidateRequests, cfg.OpenAPI.ValidateResponses))
+	}
+
+	limits := deps.RateLimits
+	if limits == nil {
+		limits = ratelimit.NewMemoryStore()
+	}
+	rateLimits := func() config.RateLimitConfig { return deps.Config().RateLimits }
+
+	api := API{
+		Users:         userController,
+		Organizations: orgController,
+		Admin:         adminController,
+		Authenticate:  authenticate,
+		Contract:      contract,
+		RateLimit: func(group string) gin.HandlerFunc {
+			return middleware.RateLimit(group, rateLimits, limits, deps.Organizations, httpLogger, deps.Metrics)
+		},
+	}
+	Register(router, api)
+	// Clients of the unversioned routes get until the sunset date to move to /v1
+	if cfg.API.UnversionedRoutes {
+		RegisterUnversioned(router, api, middleware.Deprecated(middleware.Deprecation{
+			Since:           cfg.API.DeprecationDate(),
+			Sunset:          cfg.API.SunsetDate(),
+			SuccessorPrefix: V1,
+		}, deps.Metrics))
+	}
+
+	// Every route must be described in the OpenAPI document, the tests enforce it
+	for _, route := range openapi.Undocumented(spec, router.Routes(), cfg.Metrics.Path) {
+		logging.For(deps.Logger, "app").Warn("route missing from the OpenAPI document", "route", route)
+	}
+	return router
+}
+
+// jwtKeys returns the token keys of the running configuration
+func jwtKeys(current func() *config.Config) func() utils.JWTKeys {
+	return func() utils.JWTKeys {
+		auth := current().Auth
+		keys := utils.JWTKeys{Signing: []byte(auth.JWTSecret.Value())}
+		for _, secret := range auth.PreviousJWTSecrets {
+			keys.Verification = append(keys.Verification, []byte(secret.Value()))
+		}
+		return keys
+	}
+}
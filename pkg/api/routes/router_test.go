@@ -0,0 +1,186 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/handlers"
+	"Go-api/pkg/api/openapi"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+)
+
+// newRouter builds the production router on the in-memory repositories,
+// checking every request and response against the OpenAPI document
+func newRouter(t *testing.T, configure func(*config.Config)) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.OpenAPI.ValidateRequests = true
+	cfg.OpenAPI.ValidateResponses = true
+	if configure != nil {
+		configure(cfg)
+	}
+	return routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         memory.NewUserRepository(),
+		Organizations: memory.NewOrganizationRepository(),
+		Logger:        slog.New(contractHandler{t: t}),
+	})
+}
+
+// contractHandler fails t for every response the Contract middleware finds not
+// to match the OpenAPI document, and drops everything else
+type contractHandler struct {
+	t testing.TB
+}
+
+func (h contractHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h contractHandler) Handle(_ context.Context, record slog.Record) error {
+	if record.Message != "response does not match the OpenAPI document" {
+		return nil
+	}
+	var details []string
+	record.Attrs(func(attr slog.Attr) bool {
+		details = append(details, attr.String())
+		return true
+	})
+	h.t.Errorf("%s: %s", record.Message, strings.Join(details, " "))
+	return nil
+}
+
+func (h contractHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h contractHandler) WithGroup(string) slog.Handler { return h }
+
+// assertRoutesDocumented fails t for every route in routes that has no
+// operation in the generated document, and if the document does not encode.
+// Paths in ignore are not part of the API, e.g. the metrics endpoint.
+func assertRoutesDocumented(t *testing.T, routes gin.RoutesInfo, ignore ...string) {
+	t.Helper()
+	doc := openapi.Build()
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("OpenAPI document does not encode: %v", err)
+	}
+	if len(routes) == 0 {
+		t.Fatal("no routes registered")
+	}
+	for _, route := range openapi.Undocumented(doc, routes, ignore...) {
+		t.Errorf("route %s is missing from the OpenAPI document, describe it in openapi.Build", route)
+	}
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	t.Run("Unversioned", func(t *testing.T) {
+		assertRoutesDocumented(t, newRouter(t, nil).Routes())
+	})
+	t.Run("V1Only", func(t *testing.T) {
+		router := newRouter(t, func(cfg *config.Config) { cfg.API.UnversionedRoutes = false })
+		assertRoutesDocumented(t, router.Routes())
+	})
+}
+
+func TestDocsPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newRouter(t, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, openapi.DocsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200", openapi.DocsPath, rec.Code)
+	}
+	page := rec.Body.String()
+	if !strings.Contains(page, `data-spec-url="`+openapi.SpecPath+`"`) {
+		t.Errorf("page does not point at %s", openapi.SpecPath)
+	}
+	// Everything the page needs is embedded
+	for _, external := range []string{"<script src", "<link", "http://", "https://"} {
+		if strings.Contains(page, external) {
+			t.Errorf("page loads an external resource: found %q", external)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	const origin = "https://app.example.com"
+	router := newRouter(t, func(cfg *config.Config) { cfg.Server.CORSOrigins = []string{origin} })
+
+	// Scripts may send their own request ID
+	preflight := httptest.NewRequest(http.MethodOptions, "/v1/organization/", nil)
+	preflight.Header.Set("Origin", origin)
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	preflight.Header.Set("Access-Control-Request-Headers", "authorization, x-request-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, preflight)
+	if allowed := rec.Header().Get("Access-Control-Allow-Headers"); rec.Code != http.StatusNoContent || !strings.Contains(allowed, "X-Request-ID") {
+		t.Errorf("preflight = %d allowing %q, want 204 allowing X-Request-ID", rec.Code, allowed)
+	}
+
+	// Scripts need the version, rate limit, request ID and deprecation headers
+	req := httptest.NewRequest(http.MethodPost, "/user/signin", strings.NewReader("{}"))
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	exposed := rec.Header().Get("Access-Control-Expose-Headers")
+	for _, name := range []string{"ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID", "Deprecation", "Sunset", "Link"} {
+		if !strings.Contains(exposed, name) {
+			t.Errorf("Access-Control-Expose-Headers = %q, missing %s", exposed, name)
+		}
+	}
+	for _, name := range []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "X-Request-ID", "Deprecation", "Sunset", "Link"} {
+		if rec.Header().Get(name) == "" {
+			t.Errorf("POST /user/signin did not send %s", name)
+		}
+	}
+}
+
+func TestHealthHidesErrors(t *testing.T) {
+	const cause = "dial tcp db.internal:5432: connection refused"
+	var logs bytes.Buffer
+	health := handlers.NewHealthHandler(slog.New(slog.NewTextHandler(&logs, nil)), 50*time.Millisecond,
+		handlers.Check{Name: "database", Critical: true, Probe: func(context.Context) error { return errors.New(cause) }},
+		handlers.Check{Name: "cache", Critical: true, Probe: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	)
+	cfg := config.Default()
+	router := routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         memory.NewUserRepository(),
+		Organizations: memory.NewOrganizationRepository(),
+		Health:        health,
+		Logger:        slog.New(contractHandler{t: t}),
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GET /readyz = %d, want 503", rec.Code)
+	}
+	var body struct {
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Checks["database"] != "failed" || body.Checks["cache"] != "timed out" {
+		t.Errorf("GET /readyz returned checks %v, want database failed and cache timed out", body.Checks)
+	}
+	if strings.Contains(rec.Body.String(), "db.internal") {
+		t.Errorf("GET /readyz leaks the probe error: %s", rec.Body)
+	}
+	if !strings.Contains(logs.String(), "db.internal") {
+		t.Errorf("the probe error was not logged: %s", logs.String())
+	}
+}
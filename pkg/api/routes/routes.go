@@ -0,0 +1,98 @@
+// Package routes assembles the API routes by version. Each version is served
+// under its own prefix, so response shapes can change in a new version without
+// breaking the clients of the previous one.
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/controllers"
+	"Go-api/pkg/tracing"
+)
+
+// V1 is the path prefix of the first API version
+const V1 = "/v1"
+
+// Route groups, each with its own rate limit policy in rate_limits.groups
+const (
+	GroupUser         = "user"
+	GroupOrganization = "organization"
+	GroupAdmin        = "admin"
+)
+
+// API holds what the API routes are served with
+type API struct {
+	Users         *controllers.UserController
+	Organizations *controllers.OrganizationController
+	Admin         *controllers.AdminController
+	// Authenticate checks the access token of the organization and admin routes
+	Authenticate gin.HandlerFunc
+	// Contract holds the request and response validation against the OpenAPI
+	// document, empty when it is disabled. It runs after authentication so
+	// anonymous callers learn nothing about request bodies.
+	Contract []gin.HandlerFunc
+	// RateLimit returns the rate limit of a route group, see
+	// middleware.RateLimit. The routes are not limited when it is nil.
+	RateLimit func(group string) gin.HandlerFunc
+}
+
+// limit returns the rate limit of group, if any
+func (api API) limit(group string) []gin.HandlerFunc {
+	if api.RateLimit == nil {
+		return nil
+	}
+	return []gin.HandlerFunc{api.RateLimit(group)}
+}
+
+// Register serves every API version under its prefix
+func Register(router gin.IRouter, api API) {
+	v1(router.Group(V1), api)
+}
+
+// RegisterUnversioned also serves the V1 routes at the root, where they were
+// before the API was versioned. deprecated runs before anything else, see
+// middleware.Deprecated.
+func RegisterUnversioned(router gin.IRouter, api API, deprecated gin.HandlerFunc) {
+	v1(router.Group("/", deprecated), api)
+}
+
+func v1(router *gin.RouterGroup, api API) {
+	// Create a router group for user-related routes
+	userRoutes := router.Group("/user", api.limit(GroupUser)...)
+	userRoutes.Use(api.Contract...)
+	{
+		userRoutes.POST("/signup", tracing.Handler("UserController.SignUp", api.Users.SignUp))
+		userRoutes.POST("/signin", tracing.Handler("UserController.SignIn", api.Users.SignIn))
+		userRoutes.POST("/refresh", tracing.Handler("UserController.RefreshToken", api.Users.RefreshToken))
+	}
+	// Apply JWT authentication middleware to all routes in the "/organization" group
+	orgRoutes := router.Group("/organization")
+	orgRoutes.Use(api.Authenticate)
+	orgRoutes.Use(api.limit(GroupOrganization)...)
+	orgRoutes.Use(api.Contract...)
+
+	orgRoutes.POST("/", tracing.Handler("OrganizationController.CreateOrg", api.Organizations.CreateOrg))
+	orgRoutes.GET("/", tracing.Handler("OrganizationController.ListOrgs", api.Organizations.ListOrgs))
+	orgRoutes.GET("/:organization_id", tracing.Handler("OrganizationController.GetOrgByID", api.Organizations.GetOrgByID))
+	orgRoutes.PUT("/:organization_id", tracing.Handler("OrganizationController.UpdateOrg", api.Organizations.UpdateOrg))
+	orgRoutes.PATCH("/:organization_id", tracing.Handler("OrganizationController.PatchOrg", api.Organizations.PatchOrg))
+	orgRoutes.DELETE("/:organization_id", tracing.Handler("OrganizationController.DeleteOrg", api.Organizations.DeleteOrg))
+	orgRoutes.POST("/:organization_id/invite", tracing.Handler("OrganizationController.InviteUser", api.Organizations.InviteUser))
+	orgRoutes.DELETE("/:organization_id/members/:email", tracing.Handler("OrganizationController.RemoveMember", api.Organizations.RemoveMember))
+
+	// Platform administration, across every user and organization
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(api.Authenticate)
+	adminRoutes.Use(api.limit(GroupAdmin)...)
+	adminRoutes.Use(api.Admin.RequirePlatformAdmin)
+	adminRoutes.Use(api.Contract...)
+
+	adminRoutes.GET("/users", tracing.Handler("AdminController.ListUsers", api.Admin.ListUsers))
+	adminRoutes.POST("/users/:user_id/suspend", tracing.Handler("AdminController.SuspendUser", api.Admin.SuspendUser))
+	adminRoutes.POST("/users/:user_id/deactivate", tracing.Handler("AdminController.DeactivateUser", api.Admin.DeactivateUser))
+	adminRoutes.POST("/users/:user_id/reinstate", tracing.Handler("AdminController.ReinstateUser", api.Admin.ReinstateUser))
+	adminRoutes.POST("/users/:user_id/impersonate", tracing.Handler("AdminController.Impersonate", api.Admin.Impersonate))
+	adminRoutes.GET("/organizations", tracing.Handler("AdminController.ListOrgs", api.Admin.ListOrgs))
+	adminRoutes.DELETE("/organizations/:organization_id", tracing.Handler("AdminController.ForceDeleteOrg", api.Admin.ForceDeleteOrg))
+	adminRoutes.POST("/organizations/:organization_id/restore", tracing.Handler("AdminController.RestoreOrg", api.Admin.RestoreOrg))
+}
@@ -1,25 +1,235 @@
 package pkg
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/handlers"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/store"
+	"Go-api/pkg/logging"
+	"Go-api/pkg/metrics"
+	"Go-api/pkg/ratelimit"
+	"Go-api/pkg/tracing"
 )
 
+// configWatchInterval is how often the config file is checked for changes
+const configWatchInterval = 2 * time.Second
+
+// App is the composition root: it wires the configuration, storage and router
+// together and owns their lifecycle from startup to shutdown
 type App struct {
 	*gin.Engine
+	Config *config.Live
+	Store  *store.Store
+	// Metrics is nil when metrics are disabled
+	Metrics *metrics.Metrics
+	logger  *slog.Logger
+	// root is the logger the package loggers are derived from
+	root   *slog.Logger
+	levels *logging.Levels
+	// shutdownTracing flushes the spans not exported yet
+	shutdownTracing func(context.Context) error
+	server          *http.Server
+	health          *handlers.HealthHandler
+
+	// Background workers run until shutdown, see Go
+	workers     sync.WaitGroup
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
+	workerNames []string
+}
+
+// NewApp connects to the configured database and builds the HTTP server. levels
+// are updated whenever the logging settings are reloaded.
+func NewApp(logger *slog.Logger, levels *logging.Levels, cfg *config.Config, flags config.Flags) (*App, error) {
+	// Install the tracer provider first so database commands are traced from the start
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the database selected in the config
+	db, err := store.New(logging.For(logger, "store"), cfg.Database)
+	if err != nil {
+		shutdownTracing(context.Background())
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	db.Users = tracing.TraceUsers(db.Users)
+	db.Organizations = tracing.TraceOrganizations(db.Organizations)
+
+	// Record repository latencies and errors, and count users and organizations on scrape
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		m.RegisterBusinessGauges(db.Users, db.Organizations)
+		db.Users = m.InstrumentUsers(db.Users)
+		db.Organizations = m.InstrumentOrganizations(db.Organizations)
+	}
+
+	// Routes are logged by the access log, gin's own debug output is only noise
+	if cfg.Logging.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	app := &App{
+		Config:          config.NewLive(logging.For(logger, "config"), cfg, flags),
+		Store:           db,
+		Metrics:         m,
+		logger:          logging.For(logger, "app"),
+		root:            logger,
+		levels:          levels,
+		shutdownTracing: shutdownTracing,
+		workerCtx:       workerCtx,
+		stopWorkers:     stopWorkers,
+	}
+	app.Config.OnReload(func(cfg *config.Config) {
+		if err := levels.Set(cfg.Logging); err != nil {
+			app.logger.Error("failed to apply log levels", "error", err)
+		}
+	})
+	app.routes()
+
+	app.server = &http.Server{
+		Addr:              cfg.Server.Address,
+		Handler:           app.Engine,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logging.For(logger, "http").Handler(), slog.LevelError),
+	}
+	return app, nil
 }
 
-func NewApp() *App {
-	engine := gin.Default()
-	return &App{Engine: engine}
+func (app *App) routes() {
+	// Probes for the orchestrator, and a detailed report for operators
+	app.health = handlers.NewHealthHandler(logging.For(app.root, "health"), routes.HealthCheckTimeout,
+		handlers.Check{Name: "database", Critical: true, Probe: app.Store.Ping},
+		handlers.Check{Name: "migrations", Critical: true, Probe: app.checkMigrations},
+	)
+	app.Engine = routes.New(routes.Dependencies{
+		Config:        app.Config.Current,
+		Users:         app.Store.Users,
+		Organizations: app.Store.Organizations,
+		RateLimits:    app.rateLimits(),
+		Health:        app.health,
+		Metrics:       app.Metrics,
+		Logger:        app.root,
+	})
 }
 
-// Run - starts the gin server
-func (app *App) Run(addr string) error {
-	err := app.Engine.Run(addr)
+// rateLimits returns the store shared by every replica when rate_limits.store
+// asks for it, nil for the in-process one
+func (app *App) rateLimits() ratelimit.Store {
+	if app.Config.Current().RateLimits.Store != "mongodb" {
+		return nil
+	}
+	return app.Store.RateLimits
+}
+
+// checkMigrations fails while schema migrations are pending
+func (app *App) checkMigrations(ctx context.Context) error {
+	pending, err := app.Store.PendingMigrations(ctx)
 	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d migrations pending", pending)
+	}
+	return nil
+}
+
+// Go runs fn in the background until shutdown. fn must return once ctx is done;
+// shutdown waits for it before closing the database.
+func (app *App) Go(name string, fn func(ctx context.Context)) {
+	app.workers.Add(1)
+	app.workerNames = append(app.workerNames, name)
+	go func() {
+		defer app.workers.Done()
+		fn(app.workerCtx)
+	}()
+}
+
+// Run starts the background workers and serves HTTP until ctx is done, then
+// shuts down gracefully
+func (app *App) Run(ctx context.Context) error {
+	// Reload the reloadable settings on SIGHUP or when the config file changes
+	app.Go("config watcher", func(ctx context.Context) {
+		app.Config.Watch(ctx, configWatchInterval)
+	})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		app.logger.Info("starting server", "address", app.server.Addr)
+		serveErr <- app.server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The server failed to start or stopped on its own
+		app.shutdownWorkers()
+		app.closeStore()
 		return fmt.Errorf("failed to run application: %w", err)
+	case <-ctx.Done():
+		app.logger.Info("shutdown requested")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.Config.Current().Server.ShutdownTimeout)
+	defer cancel()
+	return app.Shutdown(shutdownCtx)
+}
+
+// Shutdown stops the application in order: stop accepting connections and drain
+// in-flight requests, stop the background workers, then close the database
+func (app *App) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	// Fail readiness first so no new traffic is routed here
+	app.health.SetShuttingDown()
+
+	app.logger.Info("draining in-flight requests")
+	if err := app.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain HTTP server: %w", err))
+		// Deadline hit, cut the remaining connections
+		app.server.Close()
+	}
+
+	app.shutdownWorkers()
+
+	if err := app.closeStore(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Export the spans of the last requests
+	if err := app.shutdownTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
+	}
+
+	app.logger.Info("shutdown complete")
+	return errors.Join(errs...)
+}
+
+func (app *App) shutdownWorkers() {
+	app.logger.Info("stopping background workers", "workers", app.workerNames)
+	app.stopWorkers()
+	app.workers.Wait()
+}
+
+func (app *App) closeStore() error {
+	app.logger.Info("closing database connection")
+	if err := app.Store.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"Go-api/pkg/api/dto"
+)
+
+// The admin calls need the current user to be a platform admin signed in as
+// themselves, they fail with CodePlatformAdminRequired otherwise.
+
+// AdminListUsers returns every user whose name or email contains query, oldest
+// first. An empty query returns them all.
+func (c *Client) AdminListUsers(ctx context.Context, query string) ([]dto.AdminUserResponse, error) {
+	var resp dto.UserListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/admin/users" + searchQuery(query, false), auth: true, out: &resp}); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+// AdminSuspendUser suspends the user. They can no longer sign in and the
+// tokens they hold stop working.
+func (c *Client) AdminSuspendUser(ctx context.Context, id string) (*dto.AdminUserResponse, error) {
+	return c.adminUser(ctx, id, "/suspend")
+}
+
+// AdminDeactivateUser deactivates the user, with the same effect as a
+// suspension
+func (c *Client) AdminDeactivateUser(ctx context.Context, id string) (*dto.AdminUserResponse, error) {
+	return c.adminUser(ctx, id, "/deactivate")
+}
+
+// AdminReinstateUser makes a suspended or deactivated user active again
+func (c *Client) AdminReinstateUser(ctx context.Context, id string) (*dto.AdminUserResponse, error) {
+	return c.adminUser(ctx, id, "/reinstate")
+}
+
+// AdminImpersonate returns an access token that acts as the user until its
+// ExpiresAt. Start a separate client with it, it cannot be refreshed:
+//
+//	as := client.New(baseURL, client.WithTokens(client.Tokens{AccessToken: resp.AccessToken}))
+func (c *Client) AdminImpersonate(ctx context.Context, id, reason string) (*dto.ImpersonationResponse, error) {
+	var resp dto.ImpersonationResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   adminUserPath(id) + "/impersonate",
+		body:   dto.ImpersonateRequest{Reason: reason},
+		auth:   true,
+		out:    &resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AdminListOrganizations returns every organization whose name, description or
+// member emails contain query, oldest first. With deleted it returns the
+// force-deleted organizations instead.
+func (c *Client) AdminListOrganizations(ctx context.Context, query string, deleted bool) ([]dto.AdminOrganizationResponse, error) {
+	var resp dto.AdminOrganizationListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/admin/organizations" + searchQuery(query, deleted), auth: true, out: &resp}); err != nil {
+		return nil, err
+	}
+	return resp.Organizations, nil
+}
+
+// AdminForceDeleteOrganization deletes the organization whatever its version.
+// AdminRestoreOrganization brings it back.
+func (c *Client) AdminForceDeleteOrganization(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: adminOrganizationPath(id), auth: true})
+	return err
+}
+
+// AdminRestoreOrganization restores a force-deleted organization
+func (c *Client) AdminRestoreOrganization(ctx context.Context, id string) (*dto.AdminOrganizationResponse, error) {
+	var resp dto.AdminOrganizationResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: adminOrganizationPath(id) + "/restore", auth: true, out: &resp}); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) adminUser(ctx context.Context, id, action string) (*dto.AdminUserResponse, error) {
+	var resp dto.AdminUserResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: adminUserPath(id) + action, auth: true, out: &resp}); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func adminUserPath(id string) string {
+	return "/v1/admin/users/" + url.PathEscape(id)
+}
+
+func adminOrganizationPath(id string) string {
+	return "/v1/admin/organizations/" + url.PathEscape(id)
+}
+
+// searchQuery encodes the query parameters of the admin lists
+func searchQuery(query string, deleted bool) string {
+	values := url.Values{}
+	if query != "" {
+		values.Set("q", query)
+	}
+	if deleted {
+		values.Set("deleted", "true")
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
@@ -0,0 +1,261 @@
+// Package client is a Go SDK for the API. It speaks the request and response
+// types of pkg/api/dto, keeps the session's tokens and refreshes the access
+// token when it expires, retries idempotent calls that fail transiently, and
+// returns problem documents as *Error values that can be matched against the
+// domain errors of pkg/database/repository:
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.SignIn(ctx, email, password); err != nil {
+//		return err
+//	}
+//	org, err := c.GetOrganization(ctx, id)
+//	if errors.Is(err, repository.ErrOrganizationNotFound) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotSignedIn is returned by calls that need an access token before SignIn
+// or SetTokens
+var ErrNotSignedIn = errors.New("client: not signed in")
+
+// Tokens are the credentials of a session
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RetryPolicy controls how idempotent calls (GET, PUT and DELETE) are retried
+// after network errors and 429, 502, 503 and 504 responses. The delay doubles
+// from BaseDelay up to MaxDelay with random jitter, unless the server sends
+// Retry-After.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 1 disables retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy makes up to three attempts
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+// delay returns how long to wait before attempt+1
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Full jitter in the upper half keeps clients from retrying in lockstep
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Client calls the API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryPolicy
+	onTokens   func(Tokens)
+
+	mu     sync.Mutex
+	tokens Tokens
+	// refreshing serializes token refreshes
+	refreshing sync.Mutex
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with httpClient instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithTokens resumes a session signed in earlier
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) { c.tokens = tokens }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// OnTokens calls fn whenever the tokens change, after signing in or refreshing,
+// e.g. to store them for the next run
+func OnTokens(fn func(Tokens)) Option {
+	return func(c *Client) { c.onTokens = fn }
+}
+
+// New returns a client for the API at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c
+}
+
+// BaseURL returns the URL the API is called at
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Tokens returns the tokens of the current session
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// SetTokens replaces the tokens of the current session
+func (c *Client) SetTokens(tokens Tokens) {
+	c.mu.Lock()
+	c.tokens = tokens
+	c.mu.Unlock()
+	if c.onTokens != nil {
+		c.onTokens(tokens)
+	}
+}
+
+// request describes one API call
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   any
+	// auth sends the access token and refreshes it when it has expired
+	auth bool
+	// out receives the decoded JSON body of a successful response
+	out any
+}
+
+// do sends r and returns the headers of the successful response
+func (c *Client) do(ctx context.Context, r request) (http.Header, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, fmt.Errorf("client: failed to encode request: %w", err)
+		}
+	}
+
+	accessToken := c.Tokens().AccessToken
+	if r.auth && accessToken == "" {
+		return nil, ErrNotSignedIn
+	}
+	header, err := c.send(ctx, r, body, accessToken)
+
+	// Refresh an expired access token once and try again
+	var apiErr *Error
+	if r.auth && errors.As(err, &apiErr) && apiErr.Code == codeTokenExpired {
+		if accessToken, err = c.refresh(ctx, accessToken); err != nil {
+			return nil, err
+		}
+		header, err = c.send(ctx, r, body, accessToken)
+	}
+	return header, err
+}
+
+// send makes the attempts the retry policy allows
+func (c *Client) send(ctx context.Context, r request, body []byte, accessToken string) (http.Header, error) {
+	for attempt := 1; ; attempt++ {
+		header, err := c.roundTrip(ctx, r, body, accessToken)
+		if err == nil || attempt >= c.retry.MaxAttempts || !retryable(r.method, err) || ctx.Err() != nil {
+			return header, err
+		}
+
+		var retryAfter time.Duration
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			retryAfter = apiErr.RetryAfter
+		}
+		timer := time.NewTimer(c.retry.delay(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip makes one attempt
+func (c *Client) roundTrip(ctx context.Context, r request, body []byte, accessToken string) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, c.baseURL+r.path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to build request: %w", err)
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json, application/problem+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.auth {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, readError(resp)
+	}
+	if r.out != nil && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified {
+		if err := json.NewDecoder(resp.Body).Decode(r.out); err != nil {
+			return nil, fmt.Errorf("client: failed to decode %s %s response: %w", r.method, r.path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// retryable reports whether a failed call may be sent again: the method must be
+// idempotent and the failure transient
+func retryable(method string, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// The request may not have reached the server
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch apiErr.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/repository"
+)
+
+// codeTokenExpired is the code of a 401 that a refresh can fix
+const codeTokenExpired = problem.CodeTokenExpired
+
+// Codes returned by the controllers rather than the repositories, the others are
+// in pkg/api/problem or on the domain errors of pkg/database/repository
+const (
+	CodeUnknownUser        = "unknown_user"
+	CodeNotMember          = "not_a_member"
+	CodeInsufficientAccess = "insufficient_access_level"
+	CodeUserNotFound       = "user_not_found"
+	CodeLastAdministrator  = "last_administrator"
+	// Codes of the admin routes
+	CodePlatformAdminRequired   = "platform_admin_required"
+	CodeImpersonationNotAllowed = "impersonation_not_allowed"
+	CodeCannotSuspendSelf       = "cannot_suspend_self"
+)
+
+// Error is a problem document returned by the API, see docs/errors.md. Besides
+// errors.As, it matches with errors.Is:
+//   - the domain error with the same code, e.g. repository.ErrEmailExists
+//   - the kind of its status, e.g. repository.ErrNotFound for any 404 and
+//     repository.ErrInvalidID for invalid_user_id and invalid_organization_id
+//   - repository.ErrTimeout for database_timeout
+type Error struct {
+	Status int
+	// Code is the stable error code, empty if the response was not a problem
+	// document, e.g. from a proxy
+	Code   string
+	Detail string
+	// Fields lists the invalid fields of a validation_failed error
+	Fields []problem.FieldError
+	// RequestID identifies the request in the server logs
+	RequestID string
+	// RetryAfter is how long the server asked to wait before trying again
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("client: %d", e.Status)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	for _, field := range e.Fields {
+		msg += fmt.Sprintf("; %s %s", field.Field, field.Message)
+	}
+	return msg
+}
+
+// statusKinds maps statuses back to the domain error kinds they are returned for
+var statusKinds = map[int]error{
+	http.StatusNotFound:           repository.ErrNotFound,
+	http.StatusConflict:           repository.ErrConflict,
+	http.StatusForbidden:          repository.ErrForbidden,
+	http.StatusUnauthorized:       repository.ErrUnauthenticated,
+	http.StatusPreconditionFailed: repository.ErrPreconditionFailed,
+}
+
+func (e *Error) Is(target error) bool {
+	if domainErr, ok := target.(*repository.DomainError); ok {
+		return e.Code != "" && domainErr.Code == e.Code
+	}
+	switch target {
+	case repository.ErrInvalidID:
+		return e.Code == repository.ErrInvalidUserID.Code || e.Code == repository.ErrInvalidOrganizationID.Code
+	case repository.ErrTimeout:
+		return e.Code == problem.CodeDatabaseTimeout
+	}
+	kind, ok := statusKinds[e.Status]
+	return ok && target == kind
+}
+
+// Code returns the error code of err, empty if it is not an *Error
+func Code(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// readError decodes the problem document of a failed response
+func readError(resp *http.Response) *Error {
+	apiErr := &Error{
+		Status:    resp.StatusCode,
+		RequestID: resp.Header.Get("X-Request-ID"),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var p problem.Problem
+	if mediaType != problem.ContentType || json.Unmarshal(body, &p) != nil {
+		apiErr.Detail = strings.TrimSpace(string(body))
+		if apiErr.Detail == "" {
+			apiErr.Detail = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	apiErr.Code = p.Code
+	apiErr.Detail = p.Detail
+	apiErr.Fields = p.Errors
+	return apiErr
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// HealthReport is the status of the server and each of its dependencies
+type HealthReport struct {
+	Status        string                 `json:"status"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	Checks        map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the result of probing one dependency
+type HealthCheck struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	// Error is "failed" or "timed out" when the check is down, the server logs
+	// the details
+	Error string `json:"error,omitempty"`
+}
+
+// Live returns nil if the server is up
+func (c *Client) Live(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/healthz"})
+	return err
+}
+
+// Ready returns nil if the server accepts traffic, and an *Error with status 503
+// if it does not
+func (c *Client) Ready(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/readyz"})
+	return err
+}
+
+// Health returns the detailed health report. A report with a down critical
+// dependency is returned as an *Error with status 503.
+func (c *Client) Health(ctx context.Context) (*HealthReport, error) {
+	var report HealthReport
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/health", auth: true, out: &report}); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
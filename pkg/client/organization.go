@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"Go-api/pkg/api/dto"
+)
+
+// Organization is an organization with the ETag of the version it was read at.
+// Pass the ETag to the writes, they fail with repository.ErrVersionMismatch if
+// someone else changed the organization in between.
+type Organization struct {
+	dto.OrganizationResponse
+	ETag string
+}
+
+// CreateOrganization creates an organization with the current user as its
+// administrator and returns its ID
+func (c *Client) CreateOrganization(ctx context.Context, req dto.OrganizationRequest) (string, error) {
+	var resp dto.OrganizationCreatedResponse
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/v1/organization/", body: req, auth: true, out: &resp})
+	return resp.OrganizationID, err
+}
+
+// ListOrganizations returns the organizations the current user is a member of,
+// oldest first. They carry no ETag, get one with GetOrganization before a write.
+func (c *Client) ListOrganizations(ctx context.Context) ([]dto.OrganizationResponse, error) {
+	var resp dto.OrganizationListResponse
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/organization/", auth: true, out: &resp}); err != nil {
+		return nil, err
+	}
+	return resp.Organizations, nil
+}
+
+// GetOrganization returns the organization with its current ETag
+func (c *Client) GetOrganization(ctx context.Context, id string) (*Organization, error) {
+	return c.organization(ctx, request{method: http.MethodGet, path: organizationPath(id), auth: true})
+}
+
+// UpdateOrganization replaces the name and description of the organization at
+// the version etag
+func (c *Client) UpdateOrganization(ctx context.Context, id, etag string, req dto.OrganizationRequest) (*Organization, error) {
+	return c.organization(ctx, request{
+		method: http.MethodPut,
+		path:   organizationPath(id),
+		header: ifMatch(etag),
+		body:   req,
+		auth:   true,
+	})
+}
+
+// PatchOrganization changes the fields set in req of the organization at the
+// version etag
+func (c *Client) PatchOrganization(ctx context.Context, id, etag string, req dto.PatchOrganizationRequest) (*Organization, error) {
+	return c.organization(ctx, request{
+		method: http.MethodPatch,
+		path:   organizationPath(id),
+		header: ifMatch(etag),
+		body:   req,
+		auth:   true,
+	})
+}
+
+// DeleteOrganization deletes the organization at the version etag
+func (c *Client) DeleteOrganization(ctx context.Context, id, etag string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: organizationPath(id), header: ifMatch(etag), auth: true})
+	return err
+}
+
+// InviteMember adds the user registered with email to the organization
+func (c *Client) InviteMember(ctx context.Context, id, email string) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   organizationPath(id) + "/invite",
+		body:   dto.InviteRequest{UserEmail: email},
+		auth:   true,
+	})
+	return err
+}
+
+// RemoveMember removes the member with email from the organization. Members can
+// remove themselves, administrators anyone but the last administrator.
+func (c *Client) RemoveMember(ctx context.Context, id, email string) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   organizationPath(id) + "/members/" + url.PathEscape(email),
+		auth:   true,
+	})
+	return err
+}
+
+func (c *Client) organization(ctx context.Context, r request) (*Organization, error) {
+	org := &Organization{}
+	r.out = &org.OrganizationResponse
+	header, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	org.ETag = header.Get("ETag")
+	return org, nil
+}
+
+func organizationPath(id string) string {
+	return "/v1/organization/" + url.PathEscape(id)
+}
+
+func ifMatch(etag string) http.Header {
+	return http.Header{"If-Match": {etag}}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"Go-api/pkg/api/dto"
+)
+
+// SignUp registers a user, it does not sign in
+func (c *Client) SignUp(ctx context.Context, req dto.SignUpRequest) error {
+	_, err := c.do(ctx, request{method: http.MethodPost, path: "/v1/user/signup", body: req})
+	return err
+}
+
+// SignIn starts a session, later calls are made as this user
+func (c *Client) SignIn(ctx context.Context, email, password string) (Tokens, error) {
+	var resp dto.TokenResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/user/signin",
+		body:   dto.SignInRequest{Email: email, Password: password},
+		out:    &resp,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+	tokens := Tokens{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken}
+	c.SetTokens(tokens)
+	return tokens, nil
+}
+
+// Refresh replaces the access token using the refresh token. Calls refresh on
+// their own when the access token expires, so this is rarely needed.
+func (c *Client) Refresh(ctx context.Context) error {
+	_, err := c.refresh(ctx, c.Tokens().AccessToken)
+	return err
+}
+
+// refresh gets a new access token unless another call already replaced expired,
+// and returns the access token to use
+func (c *Client) refresh(ctx context.Context, expired string) (string, error) {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	tokens := c.Tokens()
+	if tokens.AccessToken != expired {
+		return tokens.AccessToken, nil
+	}
+	if tokens.RefreshToken == "" {
+		return "", ErrNotSignedIn
+	}
+
+	var resp dto.TokenResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/user/refresh",
+		body:   dto.RefreshRequest{RefreshToken: tokens.RefreshToken},
+		out:    &resp,
+	})
+	if err != nil {
+		return "", err
+	}
+	tokens.AccessToken = resp.AccessToken
+	c.SetTokens(tokens)
+	return tokens.AccessToken, nil
+}
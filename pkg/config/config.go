@@ -0,0 +1,261 @@
+// Package config defines the application configuration. It is loaded in layers:
+// built-in defaults, the YAML file, GOAPI_* environment variables and finally
+// -set command-line overrides, after which secrets are read from their *_file
+// paths and the result is validated.
+package config
+
+import (
+	"time"
+
+	"Go-api/pkg/database/repository"
+)
+
+type Config struct {
+	Server     ServerConfig    `yaml:"server"`
+	Database   DatabaseConfig  `yaml:"database"`
+	Auth       AuthConfig      `yaml:"auth"`
+	Logging    LoggingConfig   `yaml:"logging"`
+	Mail       MailConfig      `yaml:"mail"`
+	RateLimits RateLimitConfig `yaml:"rate_limits"`
+	Metrics    MetricsConfig   `yaml:"metrics"`
+	Tracing    TracingConfig   `yaml:"tracing"`
+	OpenAPI    OpenAPIConfig   `yaml:"openapi"`
+	API        APIConfig       `yaml:"api"`
+}
+
+type ServerConfig struct {
+	// Address is the host:port the HTTP server listens on
+	Address string `yaml:"address"`
+	// RequestTimeout bounds the context handed to the repositories for each request
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout configure the
+	// http.Server, see its documentation
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests may take to finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// CORSOrigins lists the origins allowed to call the API from a browser, "*" allows any
+	CORSOrigins []string `yaml:"cors_origins"`
+	// TrustedProxies lists the IPs and CIDRs of the proxies whose X-Forwarded-For
+	// header gives the client IP, used by the logs and rate limits
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type DatabaseConfig struct {
+	// Driver selects the backend: mongodb, postgres or memory
+	Driver string `yaml:"driver"`
+	// URI may carry credentials, so it is treated as a secret
+	URI     Secret `yaml:"uri"`
+	URIFile string `yaml:"uri_file"`
+	// Name is the MongoDB database name, Postgres takes it from the URI
+	Name string `yaml:"name"`
+	// ConnectTimeout is how long startup keeps retrying to reach the database
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	// SkipMigrations disables applying pending migrations on startup
+	SkipMigrations bool `yaml:"skip_migrations"`
+	// Timeouts bounds every repository operation
+	Timeouts repository.Timeouts `yaml:"timeouts"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs and verifies access and refresh tokens
+	JWTSecret     Secret `yaml:"jwt_secret"`
+	JWTSecretFile string `yaml:"jwt_secret_file"`
+	// PreviousJWTSecrets still verify tokens after the secret is rotated, until
+	// the tokens they signed have expired
+	PreviousJWTSecrets []Secret      `yaml:"previous_jwt_secrets"`
+	AccessTokenTTL     time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL    time.Duration `yaml:"refresh_token_ttl"`
+	// ImpersonationTokenTTL bounds the tokens platform admins issue to act as
+	// another user, they cannot be refreshed
+	ImpersonationTokenTTL time.Duration `yaml:"impersonation_token_ttl"`
+}
+
+type LoggingConfig struct {
+	// Level is one of debug, info, warn or error
+	Level string `yaml:"level"`
+	// Format is text or json
+	Format string `yaml:"format"`
+	// Packages overrides Level for the loggers of individual packages, e.g.
+	// store: debug. The audit trail ignores both and is always written.
+	Packages map[string]string `yaml:"packages"`
+}
+
+type MailConfig struct {
+	// Host is the SMTP server, mail is disabled when it is empty
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	Username     string `yaml:"username"`
+	Password     Secret `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+	From         string `yaml:"from"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RequestsPerMinute is the sustained rate allowed per client
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is how many requests a client may make at once
+	Burst int `yaml:"burst"`
+	// Store keeps the buckets: memory in each replica, or mongodb to share them
+	// between replicas, which needs the mongodb database driver
+	Store string `yaml:"store"`
+	// Groups sets the policy of the route groups user, organization and admin,
+	// replacing their default one. The rates they leave unset are
+	// RequestsPerMinute and Burst.
+	Groups map[string]RateLimitPolicy `yaml:"groups"`
+}
+
+type RateLimitPolicy struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	Burst             int `yaml:"burst"`
+	// Key is what clients are told apart by: ip, user (the authenticated user)
+	// or organization (the organization_id of the route, for its members). The
+	// last two fall back to the user then the IP on routes without one, and
+	// organization to the user for callers who are not members.
+	Key string `yaml:"key"`
+}
+
+// defaultRateLimitPolicies apply to the route groups missing from
+// rate_limits.groups. Maps cannot have defaults, the file would not merge them.
+var defaultRateLimitPolicies = map[string]RateLimitPolicy{
+	// Sign-up and sign-in are anonymous and guess passwords when abused
+	"user":         {RequestsPerMinute: 10, Burst: 5, Key: "ip"},
+	"organization": {Key: "user"},
+	"admin":        {Key: "user"},
+}
+
+// Policy returns the policy of a route group with the defaults applied
+func (c RateLimitConfig) Policy(group string) RateLimitPolicy {
+	policy, ok := c.Groups[group]
+	if !ok {
+		policy = defaultRateLimitPolicies[group]
+	}
+	if policy.Key == "" {
+		policy.Key = defaultRateLimitPolicies[group].Key
+	}
+	if policy.RequestsPerMinute == 0 {
+		policy.RequestsPerMinute = c.RequestsPerMinute
+	}
+	if policy.Burst == 0 {
+		policy.Burst = c.Burst
+	}
+	if policy.Key == "" {
+		policy.Key = "ip"
+	}
+	return policy
+}
+
+type MetricsConfig struct {
+	// Enabled exposes Prometheus metrics at Path
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Exporter is otlp to send spans to a collector or stdout to print them
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector
+	Endpoint string `yaml:"endpoint"`
+	// Insecure sends spans over plain HTTP
+	Insecure    bool   `yaml:"insecure"`
+	ServiceName string `yaml:"service_name"`
+	// SampleRatio is the fraction of new traces recorded, requests that arrive
+	// with a sampled parent are always recorded
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type OpenAPIConfig struct {
+	// ValidateRequests rejects requests that do not match the OpenAPI document
+	ValidateRequests bool `yaml:"validate_requests"`
+	// ValidateResponses logs responses that do not match the OpenAPI document.
+	// Every response body is copied to check it, enable it in development and tests.
+	ValidateResponses bool `yaml:"validate_responses"`
+}
+
+// DateFormat is the layout of the date settings
+const DateFormat = "2006-01-02"
+
+type APIConfig struct {
+	// UnversionedRoutes keeps serving the /v1 routes at the root, where they were
+	// before versioning, with Deprecation and Sunset headers
+	UnversionedRoutes bool `yaml:"unversioned_routes"`
+	// Deprecation is the date the unversioned routes were deprecated
+	Deprecation string `yaml:"deprecation"`
+	// Sunset is the date they will be removed, empty while it is not decided
+	Sunset string `yaml:"sunset"`
+}
+
+// DeprecationDate returns the Deprecation date, valid once the config is validated
+func (c APIConfig) DeprecationDate() time.Time {
+	date, _ := time.Parse(DateFormat, c.Deprecation)
+	return date
+}
+
+// SunsetDate returns the Sunset date, zero when it is not set
+func (c APIConfig) SunsetDate() time.Time {
+	date, _ := time.Parse(DateFormat, c.Sunset)
+	return date
+}
+
+// Default returns the configuration used for every setting the file, environment
+// and flags leave unset
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:           ":8080",
+			RequestTimeout:    30 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      35 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:         "mongodb",
+			URI:            "mongodb://localhost:27017",
+			Name:           "database",
+			ConnectTimeout: time.Minute,
+			Timeouts: repository.Timeouts{
+				Default: 5 * time.Second,
+			},
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+			// Long enough to reproduce a problem, short enough to leave no standing access
+			ImpersonationTokenTTL: 15 * time.Minute,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Mail: MailConfig{
+			Port: 587,
+		},
+		RateLimits: RateLimitConfig{
+			Enabled:           true,
+			RequestsPerMinute: 60,
+			Burst:             20,
+			Store:             "memory",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			ServiceName: "go-api",
+			SampleRatio: 1,
+		},
+		API: APIConfig{
+			UnversionedRoutes: true,
+			Deprecation:       "2026-10-18",
+			Sunset:            "2027-04-30",
+		},
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPrefix prefixes the environment variable of every setting, e.g.
+// GOAPI_SERVER_ADDRESS overrides server.address
+const EnvPrefix = "GOAPI_"
+
+// DefaultPath is the config file used when none is given
+const DefaultPath = "config/app-config.yaml"
+
+// legacyEnv maps environment variables used before the config system existed
+var legacyEnv = map[string]string{
+	"MONGO_URI": "database.uri",
+}
+
+// Flags are the command-line options every subcommand accepts
+type Flags struct {
+	Path      string
+	Overrides overrides
+}
+
+// Register adds -config and -set to the flag set
+func (f *Flags) Register(flags *flag.FlagSet) {
+	flags.StringVar(&f.Path, "config", DefaultPath, "path to the YAML config file")
+	flags.Var(&f.Overrides, "set", "override a setting, e.g. -set server.address=:9090 (repeatable)")
+}
+
+// Load builds the effective configuration from these flags
+func (f *Flags) Load() (*Config, error) {
+	return Load(f.Path, f.Overrides)
+}
+
+// overrides collects repeated -set key=value flags
+type overrides []string
+
+func (o *overrides) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *overrides) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// Load reads the YAML file at path on top of the defaults, then applies
+// environment variables and the key=value overrides, resolves secret files and
+// validates the result. A missing file is not an error, every setting can come
+// from the environment.
+func Load(path string, overrides []string) (*Config, error) {
+	config := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := config.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		if err := config.Set(key, value); err != nil {
+			return nil, fmt.Errorf("invalid override %q: %w", override, err)
+		}
+	}
+
+	if err := config.resolveSecretFiles(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Keys returns the dotted key of every setting that can be overridden
+func (c *Config) Keys() []string {
+	var keys []string
+	walk(reflect.ValueOf(c).Elem(), "", func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// EnvName returns the environment variable that overrides the dotted key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for env, key := range legacyEnv {
+		if value, ok := lookup(env); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+		}
+	}
+	for _, key := range c.Keys() {
+		if value, ok := lookup(EnvName(key)); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("invalid %s: %w", EnvName(key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Set parses value into the setting named by the dotted key, e.g. server.address
+func (c *Config) Set(key, value string) error {
+	var target reflect.Value
+	walk(reflect.ValueOf(c).Elem(), "", func(k string, v reflect.Value) {
+		if k == key {
+			target = v
+		}
+	})
+	if !target.IsValid() {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return setValue(target, value)
+}
+
+// walk calls fn for every leaf setting, keyed by the yaml tags of its path. Maps
+// are only configurable from the file and are skipped.
+func walk(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	walkAll(v, prefix, func(key string, v reflect.Value) {
+		if v.Kind() != reflect.Map {
+			fn(key, v)
+		}
+	})
+}
+
+// walkAll is walk including maps
+func walkAll(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkAll(field, key, fn)
+		} else {
+			fn(key, field)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(target reflect.Value, value string) error {
+	switch {
+	case target.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(d))
+	case target.Kind() == reflect.String:
+		target.SetString(value)
+	case target.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	case target.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(n))
+	case target.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		target.SetFloat(f)
+	case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.String:
+		items := reflect.MakeSlice(target.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(target.Type().Elem()))
+			}
+		}
+		target.Set(items)
+	default:
+		return fmt.Errorf("unsupported setting type %s", target.Type())
+	}
+	return nil
+}
+
+// resolveSecretFiles replaces secrets with the contents of their *_file path, as
+// used with Docker and Kubernetes secrets mounted as files
+func (c *Config) resolveSecretFiles() error {
+	secrets := []struct {
+		key    string
+		file   string
+		secret *Secret
+	}{
+		{"database.uri_file", c.Database.URIFile, &c.Database.URI},
+		{"auth.jwt_secret_file", c.Auth.JWTSecretFile, &c.Auth.JWTSecret},
+		{"mail.password_file", c.Mail.PasswordFile, &c.Mail.Password},
+	}
+	for _, s := range secrets {
+		if s.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.key, err)
+		}
+		*s.secret = Secret(strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// Redacted returns the configuration as YAML with every secret masked
+func (c *Config) Redacted() ([]byte, error) {
+	return yaml.Marshal(c)
+}
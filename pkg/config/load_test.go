@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes content to a file in a directory of the test and returns
+// its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// unsetEnv removes the variables that configure the application for the
+// duration of the test, e.g. MONGO_URI set for the database tests
+func unsetEnv(t *testing.T) {
+	t.Helper()
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if _, legacy := legacyEnv[name]; legacy || strings.HasPrefix(name, EnvPrefix) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// TestLoadPrecedence checks each layer overrides the ones before it: defaults,
+// the file, GOAPI_* variables, -set overrides and finally secret files
+func TestLoadPrecedence(t *testing.T) {
+	const file = `
+server:
+  address: ":8081"
+auth:
+  jwt_secret: from-file
+database:
+  uri: mongodb://file:27017
+`
+	secretFile := writeFile(t, "jwt_secret", "from-secret-file\n")
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		overrides []string
+		address   string
+		secret    string
+		uri       string
+	}{
+		{
+			name:    "defaults",
+			env:     map[string]string{EnvName("auth.jwt_secret"): "from-env"},
+			address: ":8080", secret: "from-env", uri: "mongodb://localhost:27017",
+		},
+		{
+			name:    "file",
+			file:    file,
+			address: ":8081", secret: "from-file", uri: "mongodb://file:27017",
+		},
+		{
+			name:    "environment",
+			file:    file,
+			env:     map[string]string{EnvName("server.address"): ":8082", EnvName("auth.jwt_secret"): "from-env", "MONGO_URI": "mongodb://legacy:27017"},
+			address: ":8082", secret: "from-env", uri: "mongodb://legacy:27017",
+		},
+		{
+			name:    "prefixed variables win over legacy ones",
+			file:    file,
+			env:     map[string]string{"MONGO_URI": "mongodb://legacy:27017", EnvName("database.uri"): "mongodb://env:27017"},
+			address: ":8081", secret: "from-file", uri: "mongodb://env:27017",
+		},
+		{
+			name:      "overrides",
+			file:      file,
+			env:       map[string]string{EnvName("server.address"): ":8082", EnvName("auth.jwt_secret"): "from-env"},
+			overrides: []string{"server.address=:8083", "auth.jwt_secret=from-set"},
+			address:   ":8083", secret: "from-set", uri: "mongodb://file:27017",
+		},
+		{
+			name:      "secret files",
+			file:      file,
+			env:       map[string]string{EnvName("auth.jwt_secret"): "from-env"},
+			overrides: []string{"auth.jwt_secret=from-set", "auth.jwt_secret_file=" + secretFile},
+			address:   ":8081", secret: "from-secret-file", uri: "mongodb://file:27017",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+			if tt.file != "" {
+				path = writeFile(t, "app-config.yaml", tt.file)
+			}
+			cfg, err := Load(path, tt.overrides)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Address != tt.address || cfg.Auth.JWTSecret.Value() != tt.secret || cfg.Database.URI.Value() != tt.uri {
+				t.Errorf("Load = address %q, secret %q, uri %q, want %q, %q, %q",
+					cfg.Server.Address, cfg.Auth.JWTSecret.Value(), cfg.Database.URI.Value(), tt.address, tt.secret, tt.uri)
+			}
+		})
+	}
+}
+
+func TestLoadSettingTypes(t *testing.T) {
+	unsetEnv(t)
+	cfg, err := Load("", []string{
+		"auth.jwt_secret=secret",
+		"server.request_timeout=10s",
+		"server.cors_origins=https://a.example.com, https://b.example.com",
+		"rate_limits.enabled=false",
+		"mail.port=2525",
+		"tracing.sample_ratio=0.5",
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.RequestTimeout != 10*time.Second || len(cfg.Server.CORSOrigins) != 2 || cfg.Server.CORSOrigins[1] != "https://b.example.com" ||
+		cfg.RateLimits.Enabled || cfg.Mail.Port != 2525 || cfg.Tracing.SampleRatio != 0.5 {
+		t.Errorf("Load = %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		overrides []string
+		want      string
+	}{
+		{name: "bad duration in the file", file: "server:\n  request_timeout: soon\n", overrides: []string{"auth.jwt_secret=secret"}, want: "failed to parse config file"},
+		{name: "bad duration in the environment", env: map[string]string{EnvName("server.request_timeout"): "soon"}, overrides: []string{"auth.jwt_secret=secret"}, want: `invalid GOAPI_SERVER_REQUEST_TIMEOUT: time: invalid duration "soon"`},
+		{name: "bad duration in an override", overrides: []string{"auth.jwt_secret=secret", "server.request_timeout=10"}, want: `invalid override "server.request_timeout=10": time: missing unit in duration "10"`},
+		{name: "unknown setting in the file", file: "server:\n  adress: \":9090\"\n", overrides: []string{"auth.jwt_secret=secret"}, want: "field adress not found"},
+		{name: "unknown setting in an override", overrides: []string{"auth.jwt_secret=secret", "server.adress=:9090"}, want: `unknown setting "server.adress"`},
+		{name: "missing secret file", overrides: []string{"auth.jwt_secret_file=/nonexistent/jwt_secret"}, want: "failed to read auth.jwt_secret_file"},
+		{name: "missing jwt secret", want: "auth.jwt_secret is required, set it or auth.jwt_secret_file (or GOAPI_AUTH_JWT_SECRET)"},
+		{name: "write timeout not longer than the request timeout", overrides: []string{"auth.jwt_secret=secret", "server.request_timeout=30s", "server.write_timeout=30s"}, want: "server.write_timeout must be longer than server.request_timeout"},
+		{name: "negative duration", overrides: []string{"auth.jwt_secret=secret", "server.idle_timeout=-1s"}, want: "server.idle_timeout must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, "app-config.yaml", tt.file)
+			}
+			_, err := Load(path, tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Server.WriteTimeout = cfg.Server.RequestTimeout
+	cfg.Logging.Level = "loud"
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("Validate = %v, want a *ValidationError", err)
+	}
+	want := []string{
+		"server.write_timeout must be longer than server.request_timeout, or responses to slow requests are cut off",
+		"auth.jwt_secret is required, set it or auth.jwt_secret_file (or GOAPI_AUTH_JWT_SECRET)",
+		`logging.level "loud" must be one of debug, info, warn or error`,
+	}
+	if fmt.Sprint(validationErr.Problems) != fmt.Sprint(want) {
+		t.Errorf("Problems = %q, want %q", validationErr.Problems, want)
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.JWTSecret = "hunter2-hunter2"
+	cfg.Database.URI = "mongodb://admin:hunter2@db:27017"
+
+	out, err := cfg.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted: %v", err)
+	}
+	for _, printed := range []string{string(out), fmt.Sprintf("%v %+v", cfg.Auth, cfg.Database)} {
+		if strings.Contains(printed, "hunter2") {
+			t.Errorf("secret printed in %s", printed)
+		}
+	}
+	if !strings.Contains(string(out), "jwt_secret: '[REDACTED]'") {
+		t.Errorf("Redacted does not mask jwt_secret:\n%s", out)
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// reloadable lists the settings, by key or "section." prefix, that take effect
+// without a restart. Every other change is reported and ignored until restart.
+var reloadable = []string{
+	"logging.level",
+	"logging.packages",
+	"rate_limits.enabled",
+	"rate_limits.requests_per_minute",
+	"rate_limits.burst",
+	"rate_limits.groups",
+	"server.cors_origins",
+	"auth.jwt_secret",
+	"auth.jwt_secret_file",
+	"auth.previous_jwt_secrets",
+}
+
+func isReloadable(key string) bool {
+	for _, r := range reloadable {
+		if key == r || (strings.HasSuffix(r, ".") && strings.HasPrefix(key, r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Live holds the running configuration and atomically swaps in the reloadable
+// settings when the config file changes or the process receives SIGHUP.
+// Components read Current on every use instead of keeping their own copy.
+type Live struct {
+	current   atomic.Pointer[Config]
+	flags     Flags
+	logger    *slog.Logger
+	mu        sync.Mutex
+	listeners []func(*Config)
+}
+
+// NewLive starts from cfg, reloads re-read the file and overrides in flags
+func NewLive(logger *slog.Logger, cfg *Config, flags Flags) *Live {
+	l := &Live{flags: flags, logger: logger}
+	l.current.Store(cfg)
+	return l
+}
+
+// Current returns the configuration in effect. The returned value must not be modified.
+func (l *Live) Current() *Config {
+	return l.current.Load()
+}
+
+// OnReload registers fn to be called with the new configuration after each
+// successful reload
+func (l *Live) OnReload(fn func(*Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Reload loads and validates the configuration again. An invalid configuration is
+// rejected as a whole and the running one stays in effect. Otherwise the
+// reloadable settings are swapped in and the names of changed settings that
+// need a restart are returned.
+func (l *Live) Reload() (restartRequired []string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loaded, err := l.flags.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	current := l.Current()
+	next := *current
+	nextValue := reflect.ValueOf(&next).Elem()
+	loadedValues := make(map[string]reflect.Value)
+	walkAll(reflect.ValueOf(loaded).Elem(), "", func(key string, v reflect.Value) {
+		loadedValues[key] = v
+	})
+
+	var applied []string
+	walkAll(nextValue, "", func(key string, v reflect.Value) {
+		newValue := loadedValues[key]
+		if reflect.DeepEqual(v.Interface(), newValue.Interface()) {
+			return
+		}
+		if !isReloadable(key) {
+			restartRequired = append(restartRequired, key)
+			return
+		}
+		v.Set(newValue)
+		applied = append(applied, key)
+	})
+
+	if len(applied) > 0 {
+		l.current.Store(&next)
+		l.logger.Info("configuration reloaded", "changed", applied)
+		for _, fn := range l.listeners {
+			fn(&next)
+		}
+	} else {
+		l.logger.Info("configuration reloaded, no reloadable settings changed")
+	}
+	if len(restartRequired) > 0 {
+		l.logger.Warn("changed settings require a restart to take effect", "settings", restartRequired)
+	}
+	return restartRequired, nil
+}
+
+// Watch reloads on SIGHUP and whenever the config file's modification time or
+// size changes, polling every interval, until ctx is done
+func (l *Live) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := l.stat()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			l.logger.Info("received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			current := l.stat()
+			if current == last {
+				continue
+			}
+			last = current
+			l.logger.Info("config file changed, reloading configuration", "path", l.flags.Path)
+		}
+
+		if _, err := l.Reload(); err != nil {
+			l.logger.Error("rejected configuration reload, keeping the running configuration", "error", err)
+		}
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (l *Live) stat() fileState {
+	info, err := os.Stat(l.flags.Path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const reloadBase = `
+server:
+  address: ":8080"
+auth:
+  jwt_secret: first-secret
+database:
+  uri: mongodb://first:27017
+logging:
+  level: info
+`
+
+// newLive loads the file at path like the server does at startup
+func newLive(t *testing.T, path string) *Live {
+	t.Helper()
+	unsetEnv(t)
+	flags := Flags{Path: path}
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return NewLive(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, flags)
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		restart []string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "reloadable",
+			file: `
+server:
+  address: ":8080"
+  cors_origins: ["https://app.example.com"]
+auth:
+  jwt_secret: second-secret
+  previous_jwt_secrets: [first-secret]
+database:
+  uri: mongodb://first:27017
+logging:
+  level: debug
+  packages:
+    store: warn
+rate_limits:
+  burst: 5
+`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Logging.Level != "debug" || cfg.Logging.Packages["store"] != "warn" || cfg.Auth.JWTSecret.Value() != "second-secret" ||
+					len(cfg.Auth.PreviousJWTSecrets) != 1 || len(cfg.Server.CORSOrigins) != 1 || cfg.RateLimits.Burst != 5 {
+					t.Errorf("reloadable settings were not swapped in: %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "restart required",
+			file: `
+server:
+  address: ":9090"
+auth:
+  jwt_secret: first-secret
+database:
+  uri: mongodb://second:27017
+logging:
+  level: warn
+rate_limits:
+  store: mongodb
+`,
+			restart: []string{"server.address", "database.uri", "rate_limits.store"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Address != ":8080" || cfg.Database.URI.Value() != "mongodb://first:27017" || cfg.RateLimits.Store != "memory" {
+					t.Errorf("settings that require a restart were swapped in: %+v", cfg)
+				}
+				if cfg.Logging.Level != "warn" {
+					t.Errorf("logging.level = %q, want warn reloaded with them", cfg.Logging.Level)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "app-config.yaml", reloadBase)
+			live := newLive(t, path)
+			before := live.Current()
+			var notified *Config
+			live.OnReload(func(cfg *Config) { notified = cfg })
+
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			restart, err := live.Reload()
+			if err != nil {
+				t.Fatalf("Reload: %v", err)
+			}
+			if !reflect.DeepEqual(restart, tt.restart) {
+				t.Errorf("Reload = %q, want %q", restart, tt.restart)
+			}
+			current := live.Current()
+			if current == before {
+				t.Fatal("the configuration was modified in place instead of swapped")
+			}
+			if notified != current {
+				t.Error("OnReload listeners were not called with the new configuration")
+			}
+			if before.Logging.Level != "info" {
+				t.Errorf("the previous configuration was modified: logging.level = %q", before.Logging.Level)
+			}
+			tt.check(t, current)
+		})
+	}
+}
+
+func TestReloadKeepsConfigurationWhen(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"nothing changed", reloadBase},
+		{"only restart settings changed", "server:\n  address: \":9090\"\nauth:\n  jwt_secret: first-secret\ndatabase:\n  uri: mongodb://first:27017\n"},
+		{"invalid", "auth:\n  jwt_secret: first-secret\nlogging:\n  level: loud\n"},
+		{"malformed", "logging: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "app-config.yaml", reloadBase)
+			live := newLive(t, path)
+			before := live.Current()
+			live.OnReload(func(*Config) { t.Error("OnReload listener called without a reloadable change") })
+
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			live.Reload()
+			if live.Current() != before {
+				t.Error("the configuration was swapped")
+			}
+		})
+	}
+}
+
+// waitFor pokes until the running configuration satisfies ok
+func waitFor(t *testing.T, live *Live, ok func(*Config) bool, poke func()) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !ok(live.Current()) {
+		if time.Now().After(deadline) {
+			t.Fatal("the configuration was not reloaded")
+		}
+		poke()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := writeFile(t, "app-config.yaml", reloadBase)
+	live := newLive(t, path)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		live.Watch(ctx, 10*time.Millisecond)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	t.Run("FileChanged", func(t *testing.T) {
+		// Watch may not have seen the file yet, every write makes it longer
+		content := reloadBase + "  packages:\n    store: debug\n"
+		waitFor(t, live, func(cfg *Config) bool { return cfg.Logging.Packages["store"] == "debug" }, func() {
+			content += "#\n"
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+		})
+	})
+
+	t.Run("SIGHUP", func(t *testing.T) {
+		// The test receives SIGHUP too, so a signal sent before Watch listens
+		// does not end the process
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+
+		// Same size and modification time, only SIGHUP reveals the change
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		content = bytes.Replace(content, []byte("store: debug"), []byte("store: warn "), 1)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+		waitFor(t, live, func(cfg *Config) bool { return cfg.Logging.Packages["store"] == "warn" }, func() {
+			syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		})
+	})
+}
@@ -0,0 +1,32 @@
+package config
+
+import "log/slog"
+
+// redacted replaces secret values whenever a configuration is printed
+const redacted = "[REDACTED]"
+
+// Secret is a string that never appears in formatted or marshalled output. Use
+// Value to read the actual secret.
+type Secret string
+
+// Value returns the secret in clear text
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// MarshalYAML redacts the secret, so printing the config cannot leak it
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// LogValue redacts the secret in structured logs
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every invalid setting found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Address); err != nil {
+		addf("server.address %q is not a host:port address", c.Server.Address)
+	}
+	if c.Server.RequestTimeout <= 0 {
+		addf("server.request_timeout must be positive")
+	}
+	for _, timeout := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	} {
+		if timeout.d < 0 {
+			addf("%s must not be negative", timeout.key)
+		}
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.WriteTimeout <= c.Server.RequestTimeout {
+		addf("server.write_timeout must be longer than server.request_timeout, or responses to slow requests are cut off")
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if u, err := url.Parse(origin); origin != "*" && (err != nil || u.Scheme == "" || u.Host == "" || u.Path != "") {
+			addf("server.cors_origins entry %q must be \"*\" or a scheme://host[:port] origin", origin)
+		}
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			addf("server.trusted_proxies entry %q must be an IP or a CIDR", proxy)
+		}
+	}
+
+	switch c.Database.Driver {
+	case "mongodb":
+		if c.Database.Name == "" {
+			addf("database.name is required for the mongodb driver")
+		}
+		fallthrough
+	case "postgres":
+		if c.Database.URI == "" {
+			addf("database.uri is required for the %s driver", c.Database.Driver)
+		}
+	case "memory":
+	default:
+		addf("database.driver %q must be one of mongodb, postgres or memory", c.Database.Driver)
+	}
+	if c.Database.ConnectTimeout < 0 {
+		addf("database.connect_timeout must not be negative")
+	}
+	if c.Database.Timeouts.Default < 0 {
+		addf("database.timeouts.default must not be negative")
+	}
+	for operation, d := range c.Database.Timeouts.Operations {
+		if d < 0 {
+			addf("database.timeouts.operations.%s must not be negative", operation)
+		}
+	}
+
+	if c.Auth.JWTSecret == "" {
+		addf("auth.jwt_secret is required, set it or auth.jwt_secret_file (or %s)", EnvName("auth.jwt_secret"))
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		addf("auth.access_token_ttl must be positive")
+	}
+	if c.Auth.RefreshTokenTTL < c.Auth.AccessTokenTTL {
+		addf("auth.refresh_token_ttl must not be shorter than auth.access_token_ttl")
+	}
+	if c.Auth.ImpersonationTokenTTL <= 0 || c.Auth.ImpersonationTokenTTL > time.Hour {
+		addf("auth.impersonation_token_ttl must be positive and at most 1h")
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		addf("logging.level %q must be one of debug, info, warn or error", c.Logging.Level)
+	}
+	packages := make([]string, 0, len(c.Logging.Packages))
+	for name := range c.Logging.Packages {
+		packages = append(packages, name)
+	}
+	sort.Strings(packages)
+	for _, name := range packages {
+		switch level := c.Logging.Packages[name]; level {
+		case "debug", "info", "warn", "error":
+		default:
+			addf("logging.packages.%s %q must be one of debug, info, warn or error", name, level)
+		}
+	}
+	switch c.Logging.Format {
+	case "text", "json":
+	default:
+		addf("logging.format %q must be text or json", c.Logging.Format)
+	}
+
+	if c.Mail.Host != "" {
+		if c.Mail.Port <= 0 || c.Mail.Port > 65535 {
+			addf("mail.port %d is not a valid port", c.Mail.Port)
+		}
+		if c.Mail.From == "" {
+			addf("mail.from is required when mail.host is set")
+		}
+	}
+
+	if c.RateLimits.Enabled {
+		if c.RateLimits.RequestsPerMinute <= 0 {
+			addf("rate_limits.requests_per_minute must be positive when rate limiting is enabled")
+		}
+		if c.RateLimits.Burst <= 0 {
+			addf("rate_limits.burst must be positive when rate limiting is enabled")
+		}
+		switch c.RateLimits.Store {
+		case "memory":
+		case "mongodb":
+			if c.Database.Driver != "mongodb" {
+				addf("rate_limits.store mongodb needs the mongodb database driver")
+			}
+		default:
+			addf("rate_limits.store %q must be memory or mongodb", c.RateLimits.Store)
+		}
+		groups := make([]string, 0, len(c.RateLimits.Groups))
+		for group := range c.RateLimits.Groups {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			policy := c.RateLimits.Groups[group]
+			switch group {
+			case "user", "organization", "admin":
+			default:
+				addf("rate_limits.groups.%s is not a route group, expected user, organization or admin", group)
+			}
+			if policy.RequestsPerMinute < 0 || policy.Burst < 0 {
+				addf("rate_limits.groups.%s rates must not be negative", group)
+			}
+			switch policy.Key {
+			case "", "ip", "user", "organization":
+			default:
+				addf("rate_limits.groups.%s.key %q must be ip, user or organization", group, policy.Key)
+			}
+		}
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		addf("metrics.path %q must start with /", c.Metrics.Path)
+	}
+
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			if c.Tracing.Endpoint == "" {
+				addf("tracing.endpoint is required for the otlp exporter")
+			}
+		case "stdout":
+		default:
+			addf("tracing.exporter %q must be otlp or stdout", c.Tracing.Exporter)
+		}
+		if c.Tracing.ServiceName == "" {
+			addf("tracing.service_name is required when tracing is enabled")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			addf("tracing.sample_ratio must be between 0 and 1")
+		}
+	}
+
+	if c.API.UnversionedRoutes {
+		deprecation, err := time.Parse(DateFormat, c.API.Deprecation)
+		if err != nil {
+			addf("api.deprecation %q must be a YYYY-MM-DD date when api.unversioned_routes is enabled", c.API.Deprecation)
+		}
+		if c.API.Sunset != "" {
+			if sunset, err := time.Parse(DateFormat, c.API.Sunset); err != nil {
+				addf("api.sunset %q must be a YYYY-MM-DD date", c.API.Sunset)
+			} else if !sunset.After(deprecation) {
+				addf("api.sunset must be after api.deprecation")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/utils"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminController serves the /admin routes, which act across every user and
+// organization. They are reserved to platform admins, see RequirePlatformAdmin,
+// and every change they make is written to the audit log.
+type AdminController struct {
+	userRepository         repository.UserRepository
+	organizationRepository repository.OrganizationRepository
+	tokens                 TokenConfig
+	logger                 *slog.Logger
+	audit                  *slog.Logger
+}
+
+func NewAdminController(logger, audit *slog.Logger, userRepository repository.UserRepository, organizationRepository repository.OrganizationRepository, tokens TokenConfig) *AdminController {
+	return &AdminController{
+		userRepository:         userRepository,
+		organizationRepository: organizationRepository,
+		tokens:                 tokens,
+		logger:                 logger,
+		audit:                  audit,
+	}
+}
+
+// RequirePlatformAdmin lets only platform admins through. It runs after
+// middleware.Authenticate; impersonation tokens are refused even when the
+// impersonator is a platform admin.
+func (c *AdminController) RequirePlatformAdmin(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		problem.Error(ctx, errImpersonationNotAllowed)
+		return
+	}
+
+	// AuthMiddleware loaded the user and refused inactive accounts
+	if !currentAdmin(ctx).PlatformAdmin {
+		problem.Error(ctx, errPlatformAdminRequired)
+		return
+	}
+	ctx.Next()
+}
+
+// currentAdmin returns the platform admin making the request
+func currentAdmin(ctx *gin.Context) *models.User {
+	return ctx.MustGet("user").(*models.User)
+}
+
+// search reports whether any of the fields contains the q query parameter,
+// ignoring case. An empty q matches everything.
+func search(ctx *gin.Context, fields ...string) bool {
+	q := strings.ToLower(strings.TrimSpace(ctx.Query("q")))
+	if q == "" {
+		return true
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), q) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *AdminController) ListUsers(ctx *gin.Context) {
+	users, err := c.userRepository.GetAllUsers(ctx.Request.Context())
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to retrieve users")
+		return
+	}
+
+	// Match the name or email
+	matches := make([]models.User, 0, len(users))
+	for _, user := range users {
+		if search(ctx, user.Name, user.Email) {
+			matches = append(matches, user)
+		}
+	}
+
+	dto.Render(ctx, http.StatusOK, dto.NewUserListResponse(matches))
+}
+
+func (c *AdminController) ListOrgs(ctx *gin.Context) {
+	deleted := false
+	switch ctx.Query("deleted") {
+	case "", "false":
+	case "true":
+		deleted = true
+	default:
+		problem.Abort(ctx, http.StatusBadRequest, problem.CodeInvalidRequest, "deleted must be true or false")
+		return
+	}
+
+	// Force-deleted organizations are kept apart from the live ones
+	list := c.organizationRepository.GetAllOrganizations
+	if deleted {
+		list = c.organizationRepository.GetDeletedOrganizations
+	}
+	orgs, err := list(ctx.Request.Context())
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to retrieve organizations")
+		return
+	}
+
+	// Match the name, description or a member's email
+	matches := make([]models.Organization, 0, len(orgs))
+	for _, org := range orgs {
+		fields := []string{org.Name, org.Description}
+		for _, member := range org.OrganizationMembers {
+			fields = append(fields, member.Email)
+		}
+		if search(ctx, fields...) {
+			matches = append(matches, org)
+		}
+	}
+
+	dto.Render(ctx, http.StatusOK, dto.NewAdminOrganizationListResponse(matches))
+}
+
+func (c *AdminController) SuspendUser(ctx *gin.Context) {
+	c.setState(ctx, models.UserSuspended, "user suspended")
+}
+
+func (c *AdminController) DeactivateUser(ctx *gin.Context) {
+	c.setState(ctx, models.UserDeactivated, "user deactivated")
+}
+
+func (c *AdminController) ReinstateUser(ctx *gin.Context) {
+	c.setState(ctx, models.UserActive, "user reinstated")
+}
+
+// setState moves the user of the user_id parameter to the account state. Their
+// tokens stop working on the next request.
+func (c *AdminController) setState(ctx *gin.Context, state, action string) {
+	admin := currentAdmin(ctx)
+	user := c.targetUser(ctx)
+	if user == nil {
+		return
+	}
+	// Nobody would be left to reinstate them
+	if state != models.UserActive && user.ID == admin.ID {
+		problem.Error(ctx, errCannotSuspendSelf)
+		return
+	}
+
+	if err := c.userRepository.SetState(ctx.Request.Context(), user.ID.Hex(), state); err != nil {
+		respondError(ctx, c.logger, err, "failed to update user")
+		return
+	}
+	previous := user.State
+	user.State = state
+
+	c.audit.InfoContext(ctx.Request.Context(), action, "admin_id", admin.ID.Hex(), "user_id", user.ID.Hex(), "email", user.Email, "previous_state", previous)
+
+	dto.Render(ctx, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// targetUser loads the user of the user_id parameter. It writes the error
+// response and returns nil when the request must not proceed.
+func (c *AdminController) targetUser(ctx *gin.Context) *models.User {
+	user, err := c.userRepository.GetUser(ctx.Request.Context(), ctx.Param("user_id"))
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
+		return nil
+	}
+	if user == nil {
+		problem.Error(ctx, errUserNotFound)
+		return nil
+	}
+	return user
+}
+
+func (c *AdminController) ForceDeleteOrg(ctx *gin.Context) {
+	// Extract organization ID from the request URL
+	orgID := ctx.Param("organization_id")
+
+	// Unlike DELETE /organization/:id there is no If-Match, and a copy is kept
+	if err := c.organizationRepository.ForceDeleteOrganization(ctx.Request.Context(), orgID); err != nil {
+		respondError(ctx, c.logger, err, "failed to delete organization")
+		return
+	}
+	c.audit.InfoContext(ctx.Request.Context(), "organization force-deleted", "admin_id", currentAdmin(ctx).ID.Hex(), "organization_id", orgID)
+
+	dto.Render(ctx, http.StatusOK, &dto.MessageResponse{Message: "organization deleted successfully, it can be restored"})
+}
+
+func (c *AdminController) RestoreOrg(ctx *gin.Context) {
+	// Extract organization ID from the request URL
+	orgID := ctx.Param("organization_id")
+
+	org, err := c.organizationRepository.RestoreOrganization(ctx.Request.Context(), orgID)
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to restore organization")
+		return
+	}
+	c.audit.InfoContext(ctx.Request.Context(), "organization restored", "admin_id", currentAdmin(ctx).ID.Hex(), "organization_id", orgID)
+
+	ctx.Header("ETag", organizationETag(org.Version))
+	dto.Render(ctx, http.StatusOK, dto.NewAdminOrganizationResponse(org))
+}
+
+// Impersonate issues an access token that acts as the user of the user_id
+// parameter until it expires. It cannot be refreshed nor used on the /admin
+// routes, and requests made with it are logged with the impersonator's ID.
+func (c *AdminController) Impersonate(ctx *gin.Context) {
+	admin := currentAdmin(ctx)
+
+	var req dto.ImpersonateRequest
+	if !dto.Bind(ctx, &req) {
+		return
+	}
+
+	user := c.targetUser(ctx)
+	if user == nil {
+		return
+	}
+	// Acting as another admin would grant their access, and inactive users have none
+	if user.PlatformAdmin || !user.Active() {
+		problem.Error(ctx, errImpersonationNotAllowed)
+		return
+	}
+
+	expiresAt := time.Now().Add(c.tokens.ImpersonationTokenTTL).UTC().Truncate(time.Second)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":               user.ID.Hex(),
+		utils.ImpersonatorClaim: admin.ID.Hex(),
+		"exp":                   expiresAt.Unix(),
+	}).SignedString(c.tokens.Keys().Signing)
+	if err != nil {
+		c.logger.ErrorContext(ctx.Request.Context(), "failed to generate JWT token", "error", err)
+		problem.Abort(ctx, http.StatusInternalServerError, problem.CodeInternalError, "failed to generate JWT token")
+		return
+	}
+	c.audit.WarnContext(ctx.Request.Context(), "impersonation started",
+		"admin_id", admin.ID.Hex(),
+		"admin_email", admin.Email,
+		"user_id", user.ID.Hex(),
+		"email", user.Email,
+		"reason", req.Reason,
+		"expires_at", expiresAt,
+	)
+
+	dto.Render(ctx, http.StatusOK, &dto.ImpersonationResponse{
+		Message:     "impersonation token issued",
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		User:        *dto.NewUserResponse(user),
+	})
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// errNotMember is returned when the current user is not a member of the organization
+	errNotMember = &repository.DomainError{Kind: repository.ErrForbidden, Code: "not_a_member", Message: "you are not a member of this organization"}
+	// errUserNotFound is returned when inviting an email nobody registered with, or
+	// when a platform admin targets a missing user
+	errUserNotFound = &repository.DomainError{Kind: repository.ErrNotFound, Code: "user_not_found", Message: "user not found"}
+	// errLastAdministrator is returned when removing the only administrator of an organization
+	errLastAdministrator = &repository.DomainError{Kind: repository.ErrConflict, Code: "last_administrator", Message: "an organization needs at least one administrator"}
+	// errPlatformAdminRequired is returned on the /admin routes to everyone but platform admins
+	errPlatformAdminRequired = &repository.DomainError{Kind: repository.ErrForbidden, Code: "platform_admin_required", Message: "this action is reserved to platform admins"}
+	// errImpersonationNotAllowed is returned when impersonating a platform admin or a
+	// user who is not active, and on the /admin routes to impersonation tokens
+	errImpersonationNotAllowed = &repository.DomainError{Kind: repository.ErrForbidden, Code: "impersonation_not_allowed", Message: "impersonation is not allowed here"}
+	// errCannotSuspendSelf is returned when a platform admin suspends or deactivates
+	// their own account
+	errCannotSuspendSelf = &repository.DomainError{Kind: repository.ErrConflict, Code: "cannot_suspend_self", Message: "you cannot suspend or deactivate your own account"}
+)
+
+// insufficientAccess is returned when a member may not perform the action, e.g.
+// "update this organization"
+func insufficientAccess(action string) error {
+	return &repository.DomainError{
+		Kind:    repository.ErrForbidden,
+		Code:    "insufficient_access_level",
+		Message: "you do not have sufficient access level to " + action,
+	}
+}
+
+// respondError writes the problem err maps to. Domain errors are expected answers;
+// anything else is logged with message, and the client only sees a generic detail.
+func respondError(ctx *gin.Context, logger *slog.Logger, err error, message string) {
+	requestCtx := ctx.Request.Context()
+	switch {
+	case repository.IsDomainError(err):
+	case errors.Is(requestCtx.Err(), context.Canceled):
+		// Nobody is listening for the response any more
+		logger.InfoContext(requestCtx, message+", client went away", "error", err)
+	case errors.Is(requestCtx.Err(), context.DeadlineExceeded):
+		logger.WarnContext(requestCtx, message+", request timed out", "error", err)
+	case errors.Is(err, repository.ErrTimeout):
+		logger.WarnContext(requestCtx, message+", database operation timed out", "error", err)
+	default:
+		logger.ErrorContext(requestCtx, message, "error", err)
+	}
+	problem.Error(ctx, err)
+}
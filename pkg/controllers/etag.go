@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// organizationETag returns the strong entity tag for the given organization version
+func organizationETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches reports whether a comma separated If-Match / If-None-Match header
+// value matches the given entity tag. If-Match requires strong comparison, so weak
+// validators only match when weak is set (If-None-Match).
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch enforces the If-Match precondition for a write to the organization.
+// It writes the error response and returns false when the write must not proceed.
+func checkIfMatch(ctx *gin.Context, org *models.Organization) bool {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		problem.Abort(ctx, http.StatusPreconditionRequired, problem.CodePreconditionRequired, "If-Match header is required")
+		return false
+	}
+	if !etagMatches(ifMatch, organizationETag(org.Version), false) {
+		ctx.Header("ETag", organizationETag(org.Version))
+		problem.Error(ctx, repository.ErrVersionMismatch)
+		return false
+	}
+	return true
+}
@@ -1,22 +1,25 @@
 package controllers
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
 	"Go-api/pkg/database/mongodb/models"
-	"Go-api/pkg/database/mongodb/repository"
+	"Go-api/pkg/database/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
 type OrganizationController struct {
-	organizationRepository *repository.OrganizationRepository
-	userRepository         *repository.UserRepository
-	logger                 *log.Logger
+	organizationRepository repository.OrganizationRepository
+	userRepository         repository.UserRepository
+	logger                 *slog.Logger
 }
 
-func NewOrganizationController(logger *log.Logger, organizationRepository *repository.OrganizationRepository, userRepository *repository.UserRepository) *OrganizationController {
+func NewOrganizationController(logger *slog.Logger, organizationRepository repository.OrganizationRepository, userRepository repository.UserRepository) *OrganizationController {
 	return &OrganizationController{
 		organizationRepository: organizationRepository,
 		userRepository:         userRepository,
@@ -24,23 +27,98 @@ func NewOrganizationController(logger *log.Logger, organizationRepository *repos
 	}
 }
 
-func (c *OrganizationController) CreateOrg(ctx *gin.Context) {
+// currentUser loads the user the access token was issued to
+func (c *OrganizationController) currentUser(ctx *gin.Context) (*models.User, error) {
+	// AuthMiddleware already loaded them
+	if user, ok := ctx.Get("user"); ok {
+		return user.(*models.User), nil
+	}
+
 	// Get current user ID from JWT token
-	userID, _ := ctx.Get("user_id")
+	userID := ctx.GetString("user_id")
+
+	user, err := c.userRepository.GetUser(ctx.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, repository.ErrUnknownUser
+	}
+	return user, nil
+}
+
+// authorize loads the organization and checks that the current user is a member,
+// and an administrator unless action is empty. It writes the error response and
+// returns nil when the request must not proceed.
+func (c *OrganizationController) authorize(ctx *gin.Context, orgID, action string) *models.Organization {
+	org, _, accessLevel, ok := c.membership(ctx, orgID)
+	if !ok {
+		return nil
+	}
+
+	if accessLevel == -1 {
+		problem.Error(ctx, errNotMember)
+		return nil
+	}
+	// Administrative actions need access level 1
+	if action != "" && accessLevel != 1 {
+		problem.Error(ctx, insufficientAccess(action))
+		return nil
+	}
+	return org
+}
+
+// membership loads the organization, the current user and their access level in
+// it, -1 if they are not a member. It writes the error response and returns
+// false when the request must not proceed.
+func (c *OrganizationController) membership(ctx *gin.Context, orgID string) (*models.Organization, *models.User, int, bool) {
+	// Retrieve organization details from repository
+	org, err := c.organizationRepository.GetOrganizationByID(ctx.Request.Context(), orgID)
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to retrieve organization")
+		return nil, nil, 0, false
+	}
+
+	// Check if the organization exists
+	if org == nil {
+		problem.Error(ctx, repository.ErrOrganizationNotFound)
+		return nil, nil, 0, false
+	}
+
+	user, err := c.currentUser(ctx)
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
+		return nil, nil, 0, false
+	}
+
+	// Suspended and deactivated users stay in the member list but have no access
+	if !user.Active() {
+		return org, user, -1, true
+	}
+
+	// Check if the user is a member of the organization
+	accessLevel, err := c.organizationRepository.GetAccessLevelByEmail(ctx.Request.Context(), orgID, user.Email)
+	if err != nil {
+		respondError(ctx, c.logger, err, "failed to check access level")
+		return nil, nil, 0, false
+	}
+	return org, user, accessLevel, true
+}
 
+func (c *OrganizationController) CreateOrg(ctx *gin.Context) {
 	// Retrieve user details from repository
-	user, err := c.userRepository.GetUser(userID.(string))
+	user, err := c.currentUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user details"})
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
 		return
 	}
 
 	// Parse request body
-	var organization models.Organization
-	if err := ctx.ShouldBindJSON(&organization); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.OrganizationRequest
+	if !dto.Bind(ctx, &req) {
 		return
 	}
+	organization := req.Organization()
 
 	// Add current user as the first member with access level 1
 	member := models.OrganizationMember{
@@ -51,234 +129,247 @@ func (c *OrganizationController) CreateOrg(ctx *gin.Context) {
 	organization.OrganizationMembers = append(organization.OrganizationMembers, member)
 
 	// Create organization
-	organizationID, err := c.organizationRepository.CreateOrganization(&organization)
+	organizationID, err := c.organizationRepository.CreateOrganization(ctx.Request.Context(), organization)
 	if err != nil {
-		if err.Error() == "organization name already exists" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "organization name already exists"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
-		}
+		respondError(ctx, c.logger, err, "failed to create organization")
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"organization_id": organizationID})
+	dto.Render(ctx, http.StatusCreated, &dto.OrganizationCreatedResponse{OrganizationID: organizationID})
 }
 
-func (c *OrganizationController) GetOrgByID(ctx *gin.Context) {
-	// Extract organization ID from the request URL
-	orgID := ctx.Param("organization_id")
-
-	// Retrieve organization details from repository
-	org, err := c.organizationRepository.GetOrganizationByID(orgID)
+func (c *OrganizationController) ListOrgs(ctx *gin.Context) {
+	user, err := c.currentUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve organization"})
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
 		return
 	}
 
-	// Check if the organization exists
-	if org == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
-		return
-	}
-
-	// Get current user ID from JWT token
-	userID, _ := ctx.Get("user_id")
-
-	// Retrieve user details using the user ID
-	user, err := c.userRepository.GetUser(userID.(string))
+	// Only the organizations the user is a member of are listed
+	orgs, err := c.organizationRepository.GetOrganizationsByMember(ctx.Request.Context(), user.Email)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user details"})
+		respondError(ctx, c.logger, err, "failed to retrieve organizations")
 		return
 	}
 
-	// Check if the user is a member of the organization
-	accessLevel, err := c.organizationRepository.GetAccessLevelByEmail(orgID, user.Email)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access level"})
+	dto.Render(ctx, http.StatusOK, dto.NewOrganizationListResponse(orgs))
+}
+
+func (c *OrganizationController) GetOrgByID(ctx *gin.Context) {
+	// Extract organization ID from the request URL
+	org := c.authorize(ctx, ctx.Param("organization_id"), "")
+	if org == nil {
 		return
 	}
 
-	if accessLevel == -1 {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "you are not a member of this organization"})
+	// Return 304 if the client already holds the current version
+	etag := organizationETag(org.Version)
+	ctx.Header("ETag", etag)
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		ctx.Status(http.StatusNotModified)
 		return
 	}
 
 	// Return organization details
-	ctx.JSON(http.StatusOK, org)
+	dto.Render(ctx, http.StatusOK, dto.NewOrganizationResponse(org))
 }
 
 func (c *OrganizationController) UpdateOrg(ctx *gin.Context) {
 	// Extract organization ID from the request URL
 	orgID := ctx.Param("organization_id")
 
-	// Retrieve organization details from repository
-	org, err := c.organizationRepository.GetOrganizationByID(orgID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve organization"})
+	org := c.authorize(ctx, orgID, "update this organization")
+	if org == nil {
 		return
 	}
 
-	// Check if the organization exists
-	if org == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+	// Reject the write if the client's copy is stale
+	if !checkIfMatch(ctx, org) {
 		return
 	}
 
-	// Get current user ID from JWT token
-	userID, _ := ctx.Get("user_id")
-
-	// Retrieve user details using the user ID
-	user, err := c.userRepository.GetUser(userID.(string))
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user details"})
+	// Parse request body
+	var req dto.OrganizationRequest
+	if !dto.Bind(ctx, &req) {
 		return
 	}
 
-	// Check if the user is a member of the organization with access level 1
-	accessLevel, err := c.organizationRepository.GetAccessLevelByEmail(orgID, user.Email)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access level"})
+	// Update organization details
+	c.writeOrganization(ctx, orgID, req.Organization(), org.Version)
+}
+
+func (c *OrganizationController) PatchOrg(ctx *gin.Context) {
+	// Extract organization ID from the request URL
+	orgID := ctx.Param("organization_id")
+
+	org := c.authorize(ctx, orgID, "update this organization")
+	if org == nil {
 		return
 	}
 
-	if accessLevel != 1 {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "you do not have sufficient access level to update this organization"})
+	// Reject the write if the client's copy is stale
+	if !checkIfMatch(ctx, org) {
 		return
 	}
 
-	// Parse request body
-	var updatedOrg models.Organization
-	if err := ctx.ShouldBindJSON(&updatedOrg); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Parse request body, only the provided fields are changed
+	var patch dto.PatchOrganizationRequest
+	if !dto.Bind(ctx, &patch) {
 		return
 	}
 
 	// Update organization details
-	err = c.organizationRepository.UpdateOrganization(orgID, &updatedOrg)
+	c.writeOrganization(ctx, orgID, patch.Apply(org), org.Version)
+}
+
+// writeOrganization stores the organization conditioned on the version the
+// client's If-Match was checked against and returns the new representation
+func (c *OrganizationController) writeOrganization(ctx *gin.Context, orgID string, org *models.Organization, version int64) {
+	updatedOrg, err := c.organizationRepository.UpdateOrganization(ctx.Request.Context(), orgID, org, version)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update organization"})
+		respondError(ctx, c.logger, err, "failed to update organization")
 		return
 	}
 
 	// Return updated organization details
-	ctx.JSON(http.StatusOK, updatedOrg)
+	ctx.Header("ETag", organizationETag(updatedOrg.Version))
+	dto.Render(ctx, http.StatusOK, dto.NewOrganizationResponse(updatedOrg))
 }
+
 func (c *OrganizationController) DeleteOrg(ctx *gin.Context) {
 	// Extract organization ID from the request URL
 	orgID := ctx.Param("organization_id")
 
-	// Retrieve organization details from repository
-	org, err := c.organizationRepository.GetOrganizationByID(orgID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve organization"})
-		return
-	}
-
-	// Check if the organization exists
+	org := c.authorize(ctx, orgID, "delete this organization")
 	if org == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
 		return
 	}
 
-	// Get current user ID from JWT token
-	userID, _ := ctx.Get("user_id")
-
-	// Retrieve user details using the user ID
-	user, err := c.userRepository.GetUser(userID.(string))
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user details"})
-		return
-	}
-
-	// Check if the user is a member of the organization with access level 1
-	accessLevel, err := c.organizationRepository.GetAccessLevelByEmail(orgID, user.Email)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access level"})
-		return
-	}
-
-	if accessLevel != 1 {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "you do not have sufficient access level to delete this organization"})
+	// Reject the delete if the client's copy is stale
+	if !checkIfMatch(ctx, org) {
 		return
 	}
 
 	// Delete organization
-	err = c.organizationRepository.DeleteOrganization(orgID)
+	err := c.organizationRepository.DeleteOrganization(ctx.Request.Context(), orgID, org.Version)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete organization"})
+		respondError(ctx, c.logger, err, "failed to delete organization")
 		return
 	}
 
 	// Return success message
-	ctx.JSON(http.StatusOK, gin.H{"message": "organization deleted successfully"})
+	dto.Render(ctx, http.StatusOK, &dto.MessageResponse{Message: "organization deleted successfully"})
 }
 
 func (c *OrganizationController) InviteUser(ctx *gin.Context) {
 	// Extract organization ID from the request URL
 	orgID := ctx.Param("organization_id")
 
-	// Retrieve organization details from repository
-	org, err := c.organizationRepository.GetOrganizationByID(orgID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve organization"})
+	if c.authorize(ctx, orgID, "invite users to this organization") == nil {
 		return
 	}
 
-	// Check if the organization exists
-	if org == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+	// Parse request body
+	var inviteData dto.InviteRequest
+	if !dto.Bind(ctx, &inviteData) {
 		return
 	}
 
-	// Get current user ID from JWT token
-	userID, _ := ctx.Get("user_id")
-
-	// Retrieve user details using the user ID
-	user, err := c.userRepository.GetUser(userID.(string))
+	// Retrieve the invited user's details
+	invitee, err := c.userRepository.GetUserByEmail(ctx.Request.Context(), inviteData.UserEmail)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve user details"})
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
+		return
+	}
+	if invitee == nil {
+		problem.Error(ctx, errUserNotFound)
 		return
 	}
 
-	// Check if the user is a member of the organization with access level 1
-	accessLevel, err := c.organizationRepository.GetAccessLevelByEmail(orgID, user.Email)
+	// Create the organization member object
+	member := models.OrganizationMember{
+		Name:        invitee.Name,
+		Email:       invitee.Email,
+		AccessLevel: 0, // Set initial access level
+	}
 
+	// Add member to organization
+	err = c.organizationRepository.AddMember(ctx.Request.Context(), orgID, &member)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check access level"})
+		respondError(ctx, c.logger, err, "failed to add member to organization")
 		return
 	}
 
-	if accessLevel != 1 {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "you do not have sufficient access level to invite users to this organization"})
+	// Return success message
+	dto.Render(ctx, http.StatusOK, &dto.MessageResponse{Message: "user invited to organization successfully"})
+}
+
+func (c *OrganizationController) RemoveMember(ctx *gin.Context) {
+	// Extract organization ID and the member's email from the request URL
+	orgID := ctx.Param("organization_id")
+	email := ctx.Param("email")
+
+	org, user, accessLevel, ok := c.membership(ctx, orgID)
+	if !ok {
 		return
 	}
-
-	// Parse request body
-	var inviteData struct {
-		UserEmail string `json:"user_email" binding:"required"`
+	if accessLevel == -1 {
+		problem.Error(ctx, errNotMember)
+		return
 	}
-	if err := ctx.ShouldBindJSON(&inviteData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Members may leave, removing someone else needs access level 1
+	if email != user.Email && accessLevel != 1 {
+		problem.Error(ctx, insufficientAccess("remove members from this organization"))
 		return
 	}
 
-	// Retrieve user details using the user ID
-	invitee, err := c.userRepository.GetUserByEmail(inviteData.UserEmail)
-
-	// Create the organization member object
-	member := models.OrganizationMember{
-		Name:        invitee.Name, // You may need to fill this with the invited user's name
-		Email:       invitee.Email,
-		AccessLevel: 0, // Set initial access level
+	// Keep at least one administrator, and one who can still sign in
+	administrators, removingAdministrator := 0, false
+	for _, member := range org.OrganizationMembers {
+		if member.AccessLevel == 1 {
+			administrators++
+			removingAdministrator = removingAdministrator || member.Email == email
+		}
+	}
+	if removingAdministrator {
+		active, err := c.activeAdministrators(ctx.Request.Context(), org)
+		if err != nil {
+			respondError(ctx, c.logger, err, "failed to retrieve user details")
+			return
+		}
+		if administrators == 1 || (len(active) == 1 && active[0] == email) {
+			problem.Error(ctx, errLastAdministrator)
+			return
+		}
 	}
 
-	// Add member to organization
-	err = c.organizationRepository.AddMember(orgID, &member)
+	// Remove member from organization
+	err := c.organizationRepository.RemoveMember(ctx.Request.Context(), orgID, email)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(ctx, c.logger, err, "failed to remove member from organization")
 		return
 	}
 
 	// Return success message
-	ctx.JSON(http.StatusOK, gin.H{"message": "user invited to organization successfully"})
+	dto.Render(ctx, http.StatusOK, &dto.MessageResponse{Message: "member removed from organization successfully"})
+}
+
+// activeAdministrators returns the emails of the administrators of org whose
+// accounts are active. Suspended and deactivated administrators are still
+// listed as members but cannot administer the organization.
+func (c *OrganizationController) activeAdministrators(ctx context.Context, org *models.Organization) ([]string, error) {
+	var emails []string
+	for _, member := range org.OrganizationMembers {
+		if member.AccessLevel != 1 {
+			continue
+		}
+		user, err := c.userRepository.GetUserByEmail(ctx, member.Email)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil && user.Active() {
+			emails = append(emails, member.Email)
+		}
+	}
+	return emails, nil
 }
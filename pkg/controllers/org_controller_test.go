@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/memory"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/logging"
+)
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		weak   bool
+		want   bool
+	}{
+		{`"3"`, false, true},
+		{`"2"`, false, false},
+		{`"2", "3"`, false, true},
+		{`*`, false, true},
+		// If-Match uses the strong comparison, a weak validator never matches
+		{`W/"3"`, false, false},
+		{`W/"3"`, true, true},
+		{`"2", W/"3"`, true, true},
+		{`W/"2"`, true, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, organizationETag(3), tt.weak); got != tt.want {
+			t.Errorf("etagMatches(%s, weak=%v) = %v, want %v", tt.header, tt.weak, got, tt.want)
+		}
+	}
+}
+
+// orgServer serves the organization routes as a member of one organization,
+// whose ID is returned
+func orgServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	users := memory.NewUserRepository()
+	orgs := memory.NewOrganizationRepository()
+	user := &models.User{Name: "Ada", Email: "ada@example.com", Password: "Secret123!"}
+	if err := users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	id, err := orgs.CreateOrganization(context.Background(), &models.Organization{
+		Name:                "Acme",
+		OrganizationMembers: []models.OrganizationMember{{Name: user.Name, Email: user.Email, AccessLevel: 1}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+
+	c := NewOrganizationController(logging.Discard(), orgs, users)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) { ctx.Set("user", user) })
+	router.GET("/organization/:organization_id", c.GetOrgByID)
+	router.PUT("/organization/:organization_id", c.UpdateOrg)
+	router.PATCH("/organization/:organization_id", c.PatchOrg)
+	router.DELETE("/organization/:organization_id", c.DeleteOrg)
+	return router, id
+}
+
+func serve(handler http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// problemCode returns the code of the problem document in rec
+func problemCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var doc struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("response is not a problem document: %v: %s", err, rec.Body)
+	}
+	return doc.Code
+}
+
+func TestGetOrgByIDConditional(t *testing.T) {
+	router, id := orgServer(t)
+	path := "/organization/" + id
+
+	rec := serve(router, http.MethodGet, path, "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"1"` {
+		t.Fatalf("GET = %d with ETag %q, want 200 with \"1\"", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	for _, ifNoneMatch := range []string{`"1"`, `W/"1"`, `"0", "1"`, `*`} {
+		rec := serve(router, http.MethodGet, path, "", http.Header{"If-None-Match": {ifNoneMatch}})
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("GET with If-None-Match %s = %d, want 304", ifNoneMatch, rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("304 response has a body: %s", rec.Body)
+		}
+		if rec.Header().Get("ETag") != `"1"` {
+			t.Errorf("304 response has ETag %q, want \"1\"", rec.Header().Get("ETag"))
+		}
+	}
+
+	rec = serve(router, http.MethodGet, path, "", http.Header{"If-None-Match": {`"0"`}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET with a stale If-None-Match = %d, want 200", rec.Code)
+	}
+}
+
+func TestWritesRequireIfMatch(t *testing.T) {
+	writes := []struct {
+		method string
+		body   string
+	}{
+		{http.MethodPut, `{"name":"Acme","description":"Anvils"}`},
+		{http.MethodPatch, `{"description":"Anvils"}`},
+		{http.MethodDelete, ""},
+	}
+	for _, write := range writes {
+		t.Run(write.method, func(t *testing.T) {
+			router, id := orgServer(t)
+			path := "/organization/" + id
+
+			rec := serve(router, write.method, path, write.body, nil)
+			if rec.Code != http.StatusPreconditionRequired || problemCode(t, rec) != problem.CodePreconditionRequired {
+				t.Fatalf("without If-Match = %d %s, want 428", rec.Code, rec.Body)
+			}
+
+			// Weak validators never satisfy If-Match
+			for _, ifMatch := range []string{`"0"`, `W/"1"`} {
+				rec = serve(router, write.method, path, write.body, http.Header{"If-Match": {ifMatch}})
+				if rec.Code != http.StatusPreconditionFailed || problemCode(t, rec) != "version_mismatch" {
+					t.Fatalf("with If-Match %s = %d %s, want 412", ifMatch, rec.Code, rec.Body)
+				}
+				if rec.Header().Get("ETag") != `"1"` {
+					t.Fatalf("412 response has ETag %q, want the current \"1\"", rec.Header().Get("ETag"))
+				}
+			}
+
+			rec = serve(router, write.method, path, write.body, http.Header{"If-Match": {`"1"`}})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("with the current If-Match = %d %s, want 200", rec.Code, rec.Body)
+			}
+			if write.method != http.MethodDelete && rec.Header().Get("ETag") != `"2"` {
+				t.Fatalf("updated organization has ETag %q, want \"2\"", rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
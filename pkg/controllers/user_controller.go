@@ -2,94 +2,101 @@ package controllers
 
 import (
 	"errors"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
-	"Go-api/pkg/database/mongodb/models"
-	"Go-api/pkg/database/mongodb/repository"
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/metrics"
+	"Go-api/pkg/utils"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
+// TokenConfig configures the JWTs issued by the user controller
+type TokenConfig struct {
+	// Keys returns the keys currently in effect, they may be rotated at runtime
+	Keys            func() utils.JWTKeys
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// ImpersonationTokenTTL is the lifetime of the tokens issued by
+	// AdminController.Impersonate
+	ImpersonationTokenTTL time.Duration
+}
+
 type UserController struct {
-	userRepository *repository.UserRepository
-	logger         *log.Logger
+	userRepository repository.UserRepository
+	tokens         TokenConfig
+	logger         *slog.Logger
+	metrics        *metrics.Metrics
 }
 
-func NewUserController(logger *log.Logger, userRepository *repository.UserRepository) *UserController {
+func NewUserController(logger *slog.Logger, userRepository repository.UserRepository, tokens TokenConfig, m *metrics.Metrics) *UserController {
 	return &UserController{
 		userRepository: userRepository,
+		tokens:         tokens,
 		logger:         logger,
+		metrics:        m,
 	}
 }
 
 func (c *UserController) SignUp(ctx *gin.Context) {
-	var user models.User
-	if err := ctx.ShouldBindJSON(&user); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate the request body
-	if user.Name == "" || user.Email == "" || user.Password == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "name, email, and password are required"})
+	var req dto.SignUpRequest
+	if !dto.Bind(ctx, &req) {
 		return
 	}
 
-	// Check if the email is already in use
-	existingUser, err1 := c.userRepository.GetUserByEmail(user.Email)
-	if err1 != nil {
-		// Handle the error (e.g., log it, return an internal server error)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
-		return
-	}
-	if existingUser != nil {
-		// Email is already in use
-		ctx.JSON(http.StatusConflict, gin.H{"error": "email already in use"})
-		return
-	}
-
-	err := c.userRepository.CreateUser(&user)
+	// Email uniqueness is enforced by the repository
+	err := c.userRepository.CreateUser(ctx.Request.Context(), req.User())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+		respondError(ctx, c.logger, err, "failed to create user")
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"message": "user created successfully"})
+	dto.Render(ctx, http.StatusCreated, &dto.MessageResponse{Message: "user created successfully"})
 }
 
 func (c *UserController) SignIn(ctx *gin.Context) {
-	var signInData struct {
-		Email    string `json:"email" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
-
-	if err := ctx.ShouldBindJSON(&signInData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var signInData dto.SignInRequest
+	if !dto.Bind(ctx, &signInData) {
 		return
 	}
 
-	user, err := c.userRepository.AuthenticateUser(signInData.Email, signInData.Password)
+	user, err := c.userRepository.AuthenticateUser(ctx.Request.Context(), signInData.Email, signInData.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, repository.ErrUnauthenticated):
+			c.logger.InfoContext(ctx.Request.Context(), "sign-in rejected", "reason", err)
+			c.metrics.Auth(metrics.AuthSignIn, metrics.OutcomeInvalidCredentials)
+		case errors.Is(err, repository.ErrUserSuspended), errors.Is(err, repository.ErrUserDeactivated):
+			c.logger.InfoContext(ctx.Request.Context(), "sign-in rejected", "reason", err, "email", signInData.Email)
+			c.metrics.Auth(metrics.AuthSignIn, metrics.OutcomeInactiveAccount)
+		default:
+			c.metrics.Auth(metrics.AuthSignIn, metrics.OutcomeError)
+		}
+		respondError(ctx, c.logger, err, "failed to authenticate user")
 		return
 	}
 
 	// Generate JWT token
 	accessToken, refreshToken, err := c.generateJWTToken(user.ID.Hex())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate JWT token"})
+		c.logger.ErrorContext(ctx.Request.Context(), "failed to generate JWT token", "error", err)
+		c.metrics.Auth(metrics.AuthSignIn, metrics.OutcomeError)
+		problem.Abort(ctx, http.StatusInternalServerError, problem.CodeInternalError, "failed to generate JWT token")
 		return
 	}
 
+	c.metrics.Auth(metrics.AuthSignIn, metrics.OutcomeSuccess)
+
 	// Return success response with JWT tokens
-	ctx.JSON(http.StatusOK, gin.H{
-		"message":       "user authenticated successfully",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
+	dto.Render(ctx, http.StatusOK, &dto.TokenResponse{
+		Message:      "user authenticated successfully",
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	})
 }
 
@@ -98,23 +105,25 @@ func (c *UserController) generateJWTToken(userID string) (string, string, error)
 	// Define JWT claims
 	accessTokenClaims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Minute * 15).Unix(), // Access token expires in 15 minutes
+		"exp":     time.Now().Add(c.tokens.AccessTokenTTL).Unix(),
 	}
 	refreshTokenClaims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // Refresh token expires in 7 days
+		"exp":     time.Now().Add(c.tokens.RefreshTokenTTL).Unix(),
 	}
 
+	secret := c.tokens.Keys().Signing
+
 	// Create access token
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	accessTokenString, err := accessToken.SignedString([]byte("your-secret-key"))
+	accessTokenString, err := accessToken.SignedString(secret)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Create refresh token
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte("your-secret-key"))
+	refreshTokenString, err := refreshToken.SignedString(secret)
 	if err != nil {
 		return "", "", err
 	}
@@ -123,56 +132,71 @@ func (c *UserController) generateJWTToken(userID string) (string, string, error)
 }
 
 func (c *UserController) RefreshToken(ctx *gin.Context) {
-	var refreshTokenData struct {
-		RefreshToken string `json:"refresh_token" binding:"required"`
-	}
-
-	if err := ctx.ShouldBindJSON(&refreshTokenData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var refreshTokenData dto.RefreshRequest
+	if !dto.Bind(ctx, &refreshTokenData) {
 		return
 	}
 
 	// Validate the refresh token and extract user ID
 	userID, err := c.validateRefreshToken(refreshTokenData.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		c.logger.InfoContext(ctx.Request.Context(), "rejected refresh token", "error", err)
+		c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeInvalidToken)
+		problem.Abort(ctx, http.StatusUnauthorized, problem.CodeInvalidRefreshToken, "invalid refresh token")
+		return
+	}
+
+	// Refuse users deleted or deactivated since they signed in
+	user, err := c.userRepository.GetUser(ctx.Request.Context(), userID)
+	if err != nil {
+		c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeError)
+		respondError(ctx, c.logger, err, "failed to retrieve user details")
+		return
+	}
+	if user == nil {
+		c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeInvalidToken)
+		problem.Error(ctx, repository.ErrUnknownUser)
+		return
+	}
+	if err := repository.StateError(user); err != nil {
+		c.logger.InfoContext(ctx.Request.Context(), "refresh rejected", "reason", err, "user_id", userID)
+		c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeInactiveAccount)
+		problem.Error(ctx, err)
 		return
 	}
 
 	// Generate a new access token
 	accessToken, _, err := c.generateJWTToken(userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate JWT token"})
+		c.logger.ErrorContext(ctx.Request.Context(), "failed to generate JWT token", "error", err)
+		c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeError)
+		problem.Abort(ctx, http.StatusInternalServerError, problem.CodeInternalError, "failed to generate JWT token")
 		return
 	}
 
+	c.metrics.Auth(metrics.AuthRefresh, metrics.OutcomeSuccess)
+
 	// Return the new access token
-	ctx.JSON(http.StatusOK, gin.H{
-		"message":      "access token refreshed successfully",
-		"access_token": accessToken,
+	dto.Render(ctx, http.StatusOK, &dto.TokenResponse{
+		Message:     "access token refreshed successfully",
+		AccessToken: accessToken,
 	})
 }
 
 // validateRefreshToken validates the refresh token and returns the user ID
 func (c *UserController) validateRefreshToken(refreshToken string) (string, error) {
-	// Parse and validate the refresh token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		// Check the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		// Return the secret key used for signing
-		return []byte("your-secret-key"), nil
-	})
-	if err != nil || !token.Valid {
+	// Parse and validate the refresh token against the current keys
+	claims, err := utils.ParseToken(refreshToken, c.tokens.Keys())
+	if err != nil {
 		return "", errors.New("invalid refresh token")
 	}
 
-	// Extract user ID from the token claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", errors.New("invalid token claims")
+	// Impersonation must end when its token expires
+	if _, ok := claims[utils.ImpersonatorClaim]; ok {
+		return "", errors.New("impersonation tokens cannot be refreshed")
 	}
+
+	// Extract user ID from the token claims
 	userID, ok := claims["user_id"].(string)
 	if !ok {
 		return "", errors.New("invalid user ID")
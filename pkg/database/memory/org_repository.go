@@ -0,0 +1,371 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrganizationRepository struct {
+	mu            sync.RWMutex
+	organizations map[primitive.ObjectID]models.Organization
+	// deleted holds the force-deleted organizations until they are restored
+	deleted map[primitive.ObjectID]models.Organization
+}
+
+var _ repository.OrganizationRepository = (*OrganizationRepository)(nil)
+
+func NewOrganizationRepository() *OrganizationRepository {
+	return &OrganizationRepository{
+		organizations: make(map[primitive.ObjectID]models.Organization),
+		deleted:       make(map[primitive.ObjectID]models.Organization),
+	}
+}
+
+// clone copies the member slice so callers never share state with the store
+func clone(org models.Organization) models.Organization {
+	if org.OrganizationMembers != nil {
+		org.OrganizationMembers = append([]models.OrganizationMember(nil), org.OrganizationMembers...)
+	}
+	return org
+}
+
+// findByName must be called with the lock held
+func (r *OrganizationRepository) findByName(name string) (models.Organization, bool) {
+	for _, org := range r.organizations {
+		if org.Name == name {
+			return org, true
+		}
+	}
+	return models.Organization{}, false
+}
+
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.findByName(org.Name); ok {
+		return "", repository.ErrOrganizationNameExists
+	}
+
+	// Every organization starts at version 1
+	org.Version = 1
+	if org.ID.IsZero() {
+		org.ID = primitive.NewObjectID()
+	}
+	r.organizations[org.ID] = clone(*org)
+	return org.ID.Hex(), nil
+}
+
+func (r *OrganizationRepository) GetOrganizationByName(ctx context.Context, name string) (*models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.findByName(name)
+	if !ok {
+		return nil, nil // Organization not found
+	}
+	org = clone(org)
+	return &org, nil
+}
+
+// checkVersion must be called with the lock held
+func (r *OrganizationRepository) checkVersion(objID primitive.ObjectID, version int64) (models.Organization, error) {
+	org, ok := r.organizations[objID]
+	if !ok {
+		return models.Organization{}, repository.ErrOrganizationNotFound
+	}
+	if org.Version != version {
+		return models.Organization{}, repository.ErrVersionMismatch
+	}
+	return org, nil
+}
+
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (*models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.checkVersion(objID, version)
+	if err != nil {
+		return nil, err
+	}
+	if other, ok := r.findByName(org.Name); ok && other.ID != objID {
+		return nil, repository.ErrOrganizationNameExists
+	}
+
+	existing.Name = org.Name
+	existing.Description = org.Description
+	if len(org.OrganizationMembers) > 0 {
+		existing.OrganizationMembers = org.OrganizationMembers
+	}
+	existing.Version++
+	existing = clone(existing)
+	r.organizations[objID] = existing
+
+	updated := clone(existing)
+	return &updated, nil
+}
+
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.checkVersion(objID, version); err != nil {
+		return err
+	}
+	delete(r.organizations, objID)
+	return nil
+}
+
+func (r *OrganizationRepository) GetAllOrganizations(ctx context.Context) ([]models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sorted(r.organizations), nil
+}
+
+// sorted copies the organizations in insertion order, it must be called with the
+// lock held
+func sorted(organizations map[primitive.ObjectID]models.Organization) []models.Organization {
+	list := make([]models.Organization, 0, len(organizations))
+	for _, org := range organizations {
+		list = append(list, clone(org))
+	}
+	// ObjectIDs start with a timestamp, so this returns them in insertion order like Mongo
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID.Hex() < list[j].ID.Hex()
+	})
+	return list
+}
+
+func (r *OrganizationRepository) GetOrganizationsByMember(ctx context.Context, email string) ([]models.Organization, error) {
+	organizations, err := r.GetAllOrganizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	memberOf := organizations[:0]
+	for _, org := range organizations {
+		for _, member := range org.OrganizationMembers {
+			if member.Email == email {
+				memberOf = append(memberOf, org)
+				break
+			}
+		}
+	}
+	return memberOf, nil
+}
+
+func (r *OrganizationRepository) GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.organizations[objID]
+	if !ok {
+		return nil, nil // Organization not found
+	}
+	org = clone(org)
+	return &org, nil
+}
+
+func (r *OrganizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return -1, repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.organizations[objID]
+	if !ok {
+		return -1, nil
+	}
+	for _, member := range org.OrganizationMembers {
+		if member.Email == email {
+			return member.AccessLevel, nil
+		}
+	}
+	return -1, nil // Email not found, return access level -1
+}
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org, ok := r.organizations[objID]
+	if !ok {
+		return repository.ErrOrganizationNotFound
+	}
+	for _, existing := range org.OrganizationMembers {
+		if existing.Email == member.Email {
+			return repository.ErrMemberExists
+		}
+	}
+
+	org = clone(org)
+	org.OrganizationMembers = append(org.OrganizationMembers, *member)
+	org.Version++
+	r.organizations[objID] = org
+	return nil
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, email string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org, ok := r.organizations[objID]
+	if !ok {
+		return repository.ErrOrganizationNotFound
+	}
+	for i, existing := range org.OrganizationMembers {
+		if existing.Email == email {
+			org = clone(org)
+			org.OrganizationMembers = append(org.OrganizationMembers[:i], org.OrganizationMembers[i+1:]...)
+			org.Version++
+			r.organizations[objID] = org
+			return nil
+		}
+	}
+	return repository.ErrMemberNotFound
+}
+
+func (r *OrganizationRepository) CountOrganizations(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.organizations)), nil
+}
+
+func (r *OrganizationRepository) ForceDeleteOrganization(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org, ok := r.organizations[objID]
+	if !ok {
+		return repository.ErrOrganizationNotFound
+	}
+	deletedAt := time.Now().UTC()
+	org.DeletedAt = &deletedAt
+	r.deleted[objID] = org
+	delete(r.organizations, objID)
+	return nil
+}
+
+func (r *OrganizationRepository) RestoreOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org, ok := r.deleted[objID]
+	if !ok {
+		return nil, repository.ErrOrganizationNotFound
+	}
+	if _, ok := r.findByName(org.Name); ok {
+		return nil, repository.ErrOrganizationNameExists
+	}
+	org.DeletedAt = nil
+	org.Version++
+	r.organizations[objID] = org
+	delete(r.deleted, objID)
+
+	restored := clone(org)
+	return &restored, nil
+}
+
+func (r *OrganizationRepository) GetDeletedOrganizations(ctx context.Context) ([]models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sorted(r.deleted), nil
+}
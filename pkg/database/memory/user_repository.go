@@ -0,0 +1,230 @@
+// Package memory provides concurrency-safe in-memory implementations of the
+// repository interfaces, for tests and for running the API without MongoDB.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[primitive.ObjectID]models.User)}
+}
+
+// findByEmail must be called with the lock held
+func (r *UserRepository) findByEmail(email string) (models.User, bool) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return models.User{}, false
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Hash outside the lock, bcrypt is deliberately slow
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.findByEmail(user.Email); ok {
+		return repository.ErrEmailExists
+	}
+
+	user.Password = string(hashedPassword)
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if user.State == "" {
+		user.State = models.UserActive
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, id string, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	// Hash the password before updating it, if provided
+	if user.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		user.Password = string(hashedPassword)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[objID]
+	if !ok {
+		return nil
+	}
+	if other, ok := r.findByEmail(user.Email); ok && other.ID != objID {
+		return repository.ErrEmailExists
+	}
+	existing.Name = user.Name
+	existing.Email = user.Email
+	if user.Password != "" {
+		existing.Password = user.Password
+	}
+	r.users[objID] = existing
+	return nil
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, objID)
+	return nil
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidUserID
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[objID]
+	if !ok {
+		return nil, nil // User not found
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.findByEmail(email)
+	if !ok {
+		return nil, nil // User not found
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) AuthenticateUser(ctx context.Context, email, password string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	user, ok := r.findByEmail(email)
+	r.mu.RUnlock()
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+
+	// Compare the provided password with the stored hash
+	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return nil, repository.ErrInvalidPassword
+	}
+	if err := repository.StateError(&user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.users)), nil
+}
+
+func (r *UserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	// ObjectIDs start with a timestamp, so this returns them in insertion order like Mongo
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID.Hex() < users[j].ID.Hex()
+	})
+	return users, nil
+}
+
+func (r *UserRepository) SetState(ctx context.Context, id string, state string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[objID]
+	if !ok {
+		return nil
+	}
+	user.State = state
+	r.users[objID] = user
+	return nil
+}
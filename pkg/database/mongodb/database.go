@@ -3,56 +3,49 @@ package database
 
 import (
 	"context"
-	"io/ioutil"
-	"log"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"gopkg.in/yaml.v2"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
-type Config struct {
-	Database struct {
-		URI  string `yaml:"uri"`
-		Name string `yaml:"name"`
-	} `yaml:"database"`
-}
-
 type DB struct {
 	Client *mongo.Client
 	DB     *mongo.Database
 }
 
-func NewDB(logger *log.Logger, configPath string) (*DB, error) {
-	// Load the configuration from the YAML file
-	configData, err := ioutil.ReadFile(configPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read database config file")
-	}
-
-	var config Config
-	err = yaml.Unmarshal(configData, &config)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal database config data")
-	}
-
+// Connect connects to MongoDB at uri and verifies the connection. Every command
+// is traced with the global OpenTelemetry tracer provider.
+func Connect(ctx context.Context, uri, name string) (*DB, error) {
 	// Set client options
-	clientOptions := options.Client().ApplyURI(config.Database.URI)
+	clientOptions := options.Client().ApplyURI(uri).SetMonitor(otelmongo.NewMonitor())
 
 	// Connect to MongoDB
-	client, err := mongo.Connect(context.Background(), clientOptions)
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to connect to MongoDB")
 	}
 
 	// Check the connection
-	err = client.Ping(context.Background(), nil)
+	err = client.Ping(ctx, nil)
 	if err != nil {
+		client.Disconnect(context.Background())
 		return nil, errors.Wrap(err, "failed to ping MongoDB")
 	}
 
-	db := client.Database(config.Database.Name)
+	db := client.Database(name)
 
 	return &DB{Client: client, DB: db}, nil
 }
+
+// Ping checks that the primary is reachable
+func (db *DB) Ping(ctx context.Context) error {
+	return db.Client.Ping(ctx, readpref.Primary())
+}
+
+// Close disconnects the MongoDB client
+func (db *DB) Close() error {
+	return db.Client.Disconnect(context.Background())
+}
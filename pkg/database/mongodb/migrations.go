@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection records the versions that have been applied
+const migrationsCollection = "schema_migrations"
+
+// Migration is one versioned change to indexes, validators or data. Up must be
+// safe to re-run, a migration interrupted before it was recorded runs again.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Migrations is the ordered list of schema migrations. Append new migrations
+// with the next version, never edit or renumber an applied one.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "unique index on user email",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("user").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetName("email_unique").SetUnique(true),
+			})
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "unique index on organization name and index on member email",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("organization").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "name", Value: 1}},
+					Options: options.Index().SetName("name_unique").SetUnique(true),
+				},
+				{
+					Keys:    bson.D{{Key: "organization_members.email", Value: 1}},
+					Options: options.Index().SetName("member_email"),
+				},
+			})
+			return err
+		},
+	},
+	{
+		Version:     3,
+		Description: "backfill organization version",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("organization").UpdateMany(ctx,
+				bson.M{"version": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"version": 1}})
+			return err
+		},
+	},
+	{
+		Version:     4,
+		Description: "JSON schema validators for user and organization",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			integer := bson.A{"int", "long"}
+			userSchema := bson.M{
+				"bsonType": "object",
+				"required": bson.A{"name", "email", "password"},
+				"properties": bson.M{
+					"name":     bson.M{"bsonType": "string"},
+					"email":    bson.M{"bsonType": "string"},
+					"password": bson.M{"bsonType": "string"},
+				},
+			}
+			organizationSchema := bson.M{
+				"bsonType": "object",
+				"required": bson.A{"name", "version"},
+				"properties": bson.M{
+					"name":        bson.M{"bsonType": "string"},
+					"description": bson.M{"bsonType": "string"},
+					"version":     bson.M{"bsonType": integer},
+					"organization_members": bson.M{
+						"bsonType": "array",
+						"items": bson.M{
+							"bsonType": "object",
+							"required": bson.A{"email", "access_level"},
+							"properties": bson.M{
+								"name":         bson.M{"bsonType": "string"},
+								"email":        bson.M{"bsonType": "string"},
+								"access_level": bson.M{"bsonType": integer},
+							},
+						},
+					},
+				},
+			}
+			if err := setValidator(ctx, db, "user", userSchema); err != nil {
+				return err
+			}
+			return setValidator(ctx, db, "organization", organizationSchema)
+		},
+	},
+	{
+		Version:     5,
+		Description: "account states replace the suspended flag",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			users := db.Collection("user")
+			if _, err := users.UpdateMany(ctx,
+				bson.M{"suspended": true, "state": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"state": "suspended"}}); err != nil {
+				return err
+			}
+			if _, err := users.UpdateMany(ctx,
+				bson.M{"state": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"state": "active"}}); err != nil {
+				return err
+			}
+			_, err := users.UpdateMany(ctx,
+				bson.M{"suspended": bson.M{"$exists": true}},
+				bson.M{"$unset": bson.M{"suspended": ""}})
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "TTL index expiring the rate limit buckets",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("rate_limits").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+			})
+			return err
+		},
+	},
+}
+
+// setValidator attaches a $jsonSchema validator to the collection, creating it if needed.
+// The moderate level leaves existing invalid documents alone until they are updated.
+func setValidator(ctx context.Context, db *mongo.Database, collection string, schema bson.M) error {
+	validator := bson.M{"$jsonSchema": schema}
+	err := db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+	}).Err()
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceNotFound" {
+		opts := options.CreateCollection().SetValidator(validator).SetValidationLevel("moderate")
+		return db.CreateCollection(ctx, collection, opts)
+	}
+	return err
+}
+
+// Migrator applies Migrations and records them in the schema_migrations collection
+type Migrator struct {
+	db         *mongo.Database
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+func NewMigrator(logger *slog.Logger, db *mongo.Database) *Migrator {
+	migrations := append([]Migration(nil), Migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return &Migrator{db: db, logger: logger, migrations: migrations}
+}
+
+// Pending returns the migrations that have not been applied yet, in order
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	cursor, err := m.db.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	var applied []struct {
+		Version int `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, fmt.Errorf("failed to decode applied migrations: %w", err)
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies pending migrations in order. With dryRun set it only logs
+// what would be applied.
+func (m *Migrator) Migrate(ctx context.Context, dryRun bool) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		m.logger.Info("MongoDB schema is up to date")
+		return nil
+	}
+
+	for _, migration := range pending {
+		if dryRun {
+			m.logger.Info("would apply migration", "version", migration.Version, "description", migration.Description)
+			continue
+		}
+
+		m.logger.Info("applying migration", "version", migration.Version, "description", migration.Description)
+		if err := migration.Up(ctx, m.db); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		_, err := m.db.Collection(migrationsCollection).InsertOne(ctx, bson.M{
+			"_id":         migration.Version,
+			"description": migration.Description,
+			"applied_at":  time.Now().UTC(),
+		})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Organization represents an organization
 type Organization struct {
@@ -8,6 +12,11 @@ type Organization struct {
 	Name                string               `bson:"name"`
 	Description         string               `bson:"description"`
 	OrganizationMembers []OrganizationMember `bson:"organization_members,omitempty"`
+	// Version is incremented on every write and exposed to clients as the ETag
+	Version int64 `bson:"version"`
+	// DeletedAt is set on organizations force-deleted by a platform admin, which
+	// can be restored
+	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
 }
 
 type OrganizationMember struct {
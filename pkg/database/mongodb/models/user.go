@@ -2,9 +2,29 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+// User is the stored user. Responses use dto.UserResponse, and the password hash
+// is excluded from JSON in case a model is ever encoded directly.
 type User struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty"`
 	Name     string             `bson:"name"`
 	Email    string             `bson:"email"`
-	Password string             `bson:"password"`
+	Password string             `bson:"password" json:"-"`
+	// PlatformAdmin grants the /admin routes, across every organization
+	PlatformAdmin bool `bson:"platform_admin"`
+	// State is one of the account states below, see Active
+	State string `bson:"state"`
+}
+
+// Account states. Suspension is meant to be temporary, deactivation is for
+// people who left; neither deletes anything.
+const (
+	UserActive      = "active"
+	UserSuspended   = "suspended"
+	UserDeactivated = "deactivated"
+)
+
+// Active reports whether the user may sign in and use their tokens. Users
+// stored before states existed have none and are active.
+func (u *User) Active() bool {
+	return u.State == "" || u.State == UserActive
 }
@@ -2,100 +2,171 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"time"
 
 	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// trashCollection holds the force-deleted organizations until they are restored
+const trashCollection = "organization_trash"
+
 type OrganizationRepository struct {
 	db *mongo.Database
 }
 
+var _ repository.OrganizationRepository = (*OrganizationRepository)(nil)
+
 func NewOrganizationRepository(db *mongo.Database) *OrganizationRepository {
 	return &OrganizationRepository{db: db}
 }
 
-func (r *OrganizationRepository) CreateOrganization(org *models.Organization) (string, error) {
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (string, error) {
 	collection := r.db.Collection("organization")
 
-	// Check if organization name already exists
-	existingOrg := r.GetOrganizationByName(org.Name)
-	if existingOrg != nil {
-		return "", errors.New("organization name already exists")
-	}
+	// Every organization starts at version 1
+	org.Version = 1
 
-	res, err := collection.InsertOne(context.Background(), org)
+	// Name uniqueness is enforced by the name_unique index
+	res, err := collection.InsertOne(ctx, org)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", repository.ErrOrganizationNameExists
+		}
 		return "", fmt.Errorf("failed to create organization: %w", err)
 	}
 	// Retrieve the ID of the newly created organization
-	organizationID := res.InsertedID.(primitive.ObjectID).Hex()
-	return organizationID, nil
+	org.ID = res.InsertedID.(primitive.ObjectID)
+	return org.ID.Hex(), nil
 }
 
-func (r *OrganizationRepository) GetOrganizationByName(name string) *models.Organization {
+func (r *OrganizationRepository) GetOrganizationByName(ctx context.Context, name string) (*models.Organization, error) {
 	var organization models.Organization
 	collection := r.db.Collection("organization")
-	err := collection.FindOne(context.Background(), bson.M{"name": name}).Decode(&organization)
+	err := collection.FindOne(ctx, bson.M{"name": name}).Decode(&organization)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil // Organization not found
+			return nil, nil // Organization not found
 		}
-		// Handle other errors if needed
-		return nil
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// versionFilter matches documents at the given version. Documents created before
+// versioning was introduced have no version field and are treated as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// writeConflict tells apart a stale version from a missing organization after a
+// conditional write matched nothing
+func (r *OrganizationRepository) writeConflict(ctx context.Context, objID primitive.ObjectID) error {
+	count, err := r.db.Collection("organization").CountDocuments(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to check organization: %w", err)
 	}
-	return &organization
+	if count == 0 {
+		return repository.ErrOrganizationNotFound
+	}
+	return repository.ErrVersionMismatch
 }
 
-func (r *OrganizationRepository) UpdateOrganization(id string, org *models.Organization) error {
+// UpdateOrganization updates the organization only if it is still at the expected
+// version, and returns the updated document
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (*models.Organization, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid organization ID")
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	set := bson.M{
+		"name":        org.Name,
+		"description": org.Description,
+	}
+	if len(org.OrganizationMembers) > 0 {
+		set["organization_members"] = org.OrganizationMembers
 	}
 
 	collection := r.db.Collection("organization")
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": org}
+	filter := bson.M{"_id": objID, "version": versionFilter(version)}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	_, err = collection.UpdateOne(context.Background(), filter, update)
+	var updated models.Organization
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
 	if err != nil {
-		return fmt.Errorf("failed to update organization: %w", err)
+		if err == mongo.ErrNoDocuments {
+			return nil, r.writeConflict(ctx, objID)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, repository.ErrOrganizationNameExists
+		}
+		return nil, fmt.Errorf("failed to update organization: %w", err)
 	}
-	return nil
+	return &updated, nil
 }
 
-func (r *OrganizationRepository) DeleteOrganization(id string) error {
+// DeleteOrganization deletes the organization only if it is still at the expected version
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid organization ID")
+		return repository.ErrInvalidOrganizationID
 	}
 
 	collection := r.db.Collection("organization")
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": objID, "version": versionFilter(version)}
 
-	_, err = collection.DeleteOne(context.Background(), filter)
+	res, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("failed to delete organization: %w", err)
 	}
+	if res.DeletedCount == 0 {
+		return r.writeConflict(ctx, objID)
+	}
 	return nil
 }
 
-func (r *OrganizationRepository) GetAllOrganizations() ([]models.Organization, error) {
+func (r *OrganizationRepository) GetAllOrganizations(ctx context.Context) ([]models.Organization, error) {
+	var organizations []models.Organization
+
+	collection := r.db.Collection("organization")
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organizations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	err = cursor.All(ctx, &organizations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode organizations: %w", err)
+	}
+
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) GetOrganizationsByMember(ctx context.Context, email string) ([]models.Organization, error) {
 	var organizations []models.Organization
 
 	collection := r.db.Collection("organization")
-	cursor, err := collection.Find(context.Background(), bson.M{})
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := collection.Find(ctx, bson.M{"organization_members.email": email}, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve organizations: %w", err)
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	err = cursor.All(context.Background(), &organizations)
+	err = cursor.All(ctx, &organizations)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode organizations: %w", err)
 	}
@@ -103,16 +174,16 @@ func (r *OrganizationRepository) GetAllOrganizations() ([]models.Organization, e
 	return organizations, nil
 }
 
-func (r *OrganizationRepository) GetOrganizationByID(id string) (*models.Organization, error) {
+func (r *OrganizationRepository) GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid organization ID")
+		return nil, repository.ErrInvalidOrganizationID
 	}
 
 	var organization models.Organization
 
 	collection := r.db.Collection("organization")
-	err = collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&organization)
+	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&organization)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil // Organization not found
@@ -123,10 +194,10 @@ func (r *OrganizationRepository) GetOrganizationByID(id string) (*models.Organiz
 	return &organization, nil
 }
 
-func (r *OrganizationRepository) GetAccessLevelByEmail(organizationID, email string) (int, error) {
+func (r *OrganizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (int, error) {
 	objID, err := primitive.ObjectIDFromHex(organizationID)
 	if err != nil {
-		return -1, errors.New("invalid organization ID")
+		return -1, repository.ErrInvalidOrganizationID
 	}
 
 	collection := r.db.Collection("organization")
@@ -134,12 +205,14 @@ func (r *OrganizationRepository) GetAccessLevelByEmail(organizationID, email str
 		"_id":                        objID,
 		"organization_members.email": email,
 	}
+	// Only project the matching member, not the first one in the array
+	opts := options.FindOne().SetProjection(bson.M{"organization_members.$": 1})
 
 	var result struct {
 		OrganizationMembers []models.OrganizationMember `bson:"organization_members"`
 	}
 
-	err = collection.FindOne(context.Background(), filter).Decode(&result)
+	err = collection.FindOne(ctx, filter, opts).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return -1, nil // Email not found, return access level -1
@@ -154,34 +227,159 @@ func (r *OrganizationRepository) GetAccessLevelByEmail(organizationID, email str
 	return result.OrganizationMembers[0].AccessLevel, nil
 }
 
-func (r *OrganizationRepository) AddMember(organizationID string, member *models.OrganizationMember) error {
+func (r *OrganizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) error {
 	objID, err := primitive.ObjectIDFromHex(organizationID)
 	if err != nil {
-		return errors.New("invalid organization ID")
+		return repository.ErrInvalidOrganizationID
 	}
 
 	collection := r.db.Collection("organization")
+
+	// Only push if the email is not already a member, in a single atomic update
 	filter := bson.M{"_id": objID, "organization_members.email": bson.M{"$ne": member.Email}}
+	update := bson.M{
+		"$push": bson.M{"organization_members": member},
+		"$inc":  bson.M{"version": 1},
+	}
 
-	// Check if the email already exists in the organization
-	var result struct {
-		OrganizationMembers []models.OrganizationMember `bson:"organization_members"`
+	res, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to add member to organization: %w", err)
 	}
+	if res.MatchedCount == 0 {
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": objID})
+		if err != nil {
+			return fmt.Errorf("failed to check existing member: %w", err)
+		}
+		if count == 0 {
+			return repository.ErrOrganizationNotFound
+		}
+		return repository.ErrMemberExists
+	}
+	return nil
+}
 
-	err = collection.FindOne(context.Background(), filter).Decode(&result)
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, email string) error {
+	objID, err := primitive.ObjectIDFromHex(organizationID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("email already exists in the organization")
+		return repository.ErrInvalidOrganizationID
+	}
+
+	collection := r.db.Collection("organization")
+
+	// Only pull if the email is a member, in a single atomic update
+	filter := bson.M{"_id": objID, "organization_members.email": email}
+	update := bson.M{
+		"$pull": bson.M{"organization_members": bson.M{"email": email}},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	res, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": objID})
+		if err != nil {
+			return fmt.Errorf("failed to check existing member: %w", err)
+		}
+		if count == 0 {
+			return repository.ErrOrganizationNotFound
 		}
-		return fmt.Errorf("failed to check existing member: %w", err)
+		return repository.ErrMemberNotFound
 	}
+	return nil
+}
 
-	// If the email doesn't exist, proceed to add the member
-	update := bson.M{"$push": bson.M{"organization_members": member}}
+func (r *OrganizationRepository) CountOrganizations(ctx context.Context) (int64, error) {
+	count, err := r.db.Collection("organization").CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organizations: %w", err)
+	}
+	return count, nil
+}
 
-	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": objID}, update)
+// ForceDeleteOrganization copies the organization to the trash collection before
+// deleting it, so a failure in between leaves the organization in place
+func (r *OrganizationRepository) ForceDeleteOrganization(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("failed to add member to organization: %w", err)
+		return repository.ErrInvalidOrganizationID
+	}
+
+	collection := r.db.Collection("organization")
+	trash := r.db.Collection(trashCollection)
+
+	var organization models.Organization
+	if err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&organization); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return repository.ErrOrganizationNotFound
+		}
+		return fmt.Errorf("failed to retrieve organization: %w", err)
+	}
+	deletedAt := time.Now().UTC()
+	organization.DeletedAt = &deletedAt
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := trash.ReplaceOne(ctx, bson.M{"_id": objID}, organization, opts); err != nil {
+		return fmt.Errorf("failed to keep a copy of the organization: %w", err)
+	}
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		// Deleted by someone else in the meantime, there is nothing to restore
+		trash.DeleteOne(ctx, bson.M{"_id": objID})
+		return repository.ErrOrganizationNotFound
 	}
 	return nil
 }
+
+// RestoreOrganization inserts the copy back before removing it from the trash
+func (r *OrganizationRepository) RestoreOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	trash := r.db.Collection(trashCollection)
+
+	var organization models.Organization
+	if err := trash.FindOne(ctx, bson.M{"_id": objID}).Decode(&organization); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repository.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve deleted organization: %w", err)
+	}
+	organization.DeletedAt = nil
+	organization.Version++
+
+	// Name uniqueness is enforced by the name_unique index
+	if _, err := r.db.Collection("organization").InsertOne(ctx, organization); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, repository.ErrOrganizationNameExists
+		}
+		return nil, fmt.Errorf("failed to restore organization: %w", err)
+	}
+	if _, err := trash.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return nil, fmt.Errorf("failed to remove the restored organization from the trash: %w", err)
+	}
+	return &organization, nil
+}
+
+func (r *OrganizationRepository) GetDeletedOrganizations(ctx context.Context) ([]models.Organization, error) {
+	var organizations []models.Organization
+
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.db.Collection(trashCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve deleted organizations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &organizations); err != nil {
+		return nil, fmt.Errorf("failed to decode deleted organizations: %w", err)
+	}
+	return organizations, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"Go-api/pkg/ratelimit"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RateLimitStore keeps the rate limit buckets in the rate_limits collection, so
+// every replica draws from the same ones. A take is a single atomic update, and
+// buckets expire once they are full again.
+type RateLimitStore struct {
+	// Now returns the current time, tests replace it
+	Now func() time.Time
+
+	db *mongo.Database
+}
+
+var _ ratelimit.Store = (*RateLimitStore)(nil)
+
+func NewRateLimitStore(db *mongo.Database) *RateLimitStore {
+	return &RateLimitStore{Now: time.Now, db: db}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	now := s.Now()
+	burst := float64(limit.Burst)
+	perMillisecond := float64(limit.PerMinute) / float64(time.Minute/time.Millisecond)
+
+	// Refill for the time elapsed since the last take, a new bucket starts full.
+	// Clocks of the replicas may disagree, a take from the past refills nothing.
+	elapsed := bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}}
+	refilled := bson.M{"$min": bson.A{burst, bson.M{"$add": bson.A{
+		bson.M{"$ifNull": bson.A{"$tokens", burst}},
+		bson.M{"$multiply": bson.A{elapsed, perMillisecond}},
+	}}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"tokens": refilled, "updated_at": now}}},
+		{{Key: "$set", Value: bson.M{"allowed": bson.M{"$gte": bson.A{"$tokens", 1}}}}},
+		{{Key: "$set", Value: bson.M{"tokens": bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}}}}},
+		// Removed by the TTL index once full again, it would behave like a new one
+		{{Key: "$set", Value: bson.M{"expires_at": bson.M{"$add": bson.A{now, bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{burst, "$tokens"}}, perMillisecond}}}}}}},
+	}
+
+	var bucket struct {
+		Tokens  float64 `bson:"tokens"`
+		Allowed bool    `bson:"allowed"`
+	}
+	err := s.db.Collection("rate_limits").FindOneAndUpdate(ctx, bson.M{"_id": key}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&bucket)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	return ratelimit.NewResult(bucket.Allowed, bucket.Tokens, limit), nil
+}
@@ -2,13 +2,14 @@ package repository
 
 import (
 	"context"
-	"errors"
 
 	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -16,29 +17,44 @@ type UserRepository struct {
 	db *mongo.Database
 }
 
+var _ repository.UserRepository = (*UserRepository)(nil)
+
 func NewUserRepository(db *mongo.Database) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) CreateUser(user *models.User) error {
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	// Hash the password before storing it in the database
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 	user.Password = string(hashedPassword)
+	if user.State == "" {
+		user.State = models.UserActive
+	}
 
-	_, err = r.db.Collection("user").InsertOne(context.Background(), user)
+	// Email uniqueness is enforced by the email_unique index
+	res, err := r.db.Collection("user").InsertOne(ctx, user)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return repository.ErrEmailExists
+		}
 		return err
 	}
+	user.ID = res.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
-func (r *UserRepository) UpdateUser(id string, user *models.User) error {
+func (r *UserRepository) UpdateUser(ctx context.Context, id string, user *models.User) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid user ID")
+		return repository.ErrInvalidUserID
+	}
+
+	set := bson.M{
+		"name":  user.Name,
+		"email": user.Email,
 	}
 
 	// Hash the password before updating it in the database, if provided
@@ -48,44 +64,40 @@ func (r *UserRepository) UpdateUser(id string, user *models.User) error {
 			return err
 		}
 		user.Password = string(hashedPassword)
+		set["password"] = user.Password
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"name":     user.Name,
-			"email":    user.Email,
-			"password": user.Password,
-		},
-	}
-
-	_, err = r.db.Collection("user").UpdateOne(context.Background(), bson.M{"_id": objID}, update)
+	_, err = r.db.Collection("user").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return repository.ErrEmailExists
+		}
 		return err
 	}
 	return nil
 }
 
-func (r *UserRepository) DeleteUser(id string) error {
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid user ID")
+		return repository.ErrInvalidUserID
 	}
 
-	_, err = r.db.Collection("user").DeleteOne(context.Background(), bson.M{"_id": objID})
+	_, err = r.db.Collection("user").DeleteOne(ctx, bson.M{"_id": objID})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *UserRepository) GetUser(id string) (*models.User, error) {
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid user ID")
+		return nil, repository.ErrInvalidUserID
 	}
 
 	var user models.User
-	err = r.db.Collection("user").FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user)
+	err = r.db.Collection("user").FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil // User not found
@@ -95,9 +107,9 @@ func (r *UserRepository) GetUser(id string) (*models.User, error) {
 
 	return &user, nil
 }
-func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Collection("user").FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
+	err := r.db.Collection("user").FindOne(ctx, bson.M{"email": email}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil // User not found
@@ -108,12 +120,12 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) AuthenticateUser(email, password string) (*models.User, error) {
+func (r *UserRepository) AuthenticateUser(ctx context.Context, email, password string) (*models.User, error) {
 	var user models.User
-	err := r.db.Collection("user").FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
+	err := r.db.Collection("user").FindOne(ctx, bson.M{"email": email}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -121,8 +133,45 @@ func (r *UserRepository) AuthenticateUser(email, password string) (*models.User,
 	// Compare the provided password with the hashed password from the database
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, errors.New("invalid password")
+		return nil, repository.ErrInvalidPassword
+	}
+	if err := repository.StateError(&user); err != nil {
+		return nil, err
 	}
 
 	return &user, nil
 }
+
+func (r *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	count, err := r.db.Collection("user").CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *UserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.db.Collection("user").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) SetState(ctx context.Context, id string, state string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	_, err = r.db.Collection("user").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"state": state}})
+	return err
+}
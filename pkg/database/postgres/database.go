@@ -0,0 +1,30 @@
+// Package postgres implements the repository interfaces on top of database/sql.
+// It targets PostgreSQL in production; the schema and queries stay within the
+// subset SQLite also understands so the backend can be exercised locally
+// without a Postgres server.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	// Register the "postgres" database/sql driver
+	_ "github.com/lib/pq"
+)
+
+// Open connects to PostgreSQL and verifies the connection. Callers apply pending
+// migrations with Migrate.
+func Open(ctx context.Context, uri string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Codes of a unique constraint violation
+const (
+	// pqUniqueViolation is the PostgreSQL SQLSTATE
+	pqUniqueViolation = "23505"
+	// sqliteConstraintUnique is SQLITE_CONSTRAINT_UNIQUE, the extended result
+	// code of the SQLite drivers
+	sqliteConstraintUnique = 2067
+)
+
+// uniqueViolation reports whether err is a unique constraint violation, from
+// PostgreSQL or SQLite. Any other failure must not be reported as a conflict.
+func uniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqUniqueViolation
+	}
+	// The SQLite driver is only linked into tests, its errors carry the code
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqliteConstraintUnique
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+CREATE TABLE users (
+    id       CHAR(24) PRIMARY KEY,
+    name     TEXT     NOT NULL,
+    email    TEXT     NOT NULL,
+    password TEXT     NOT NULL,
+    CONSTRAINT users_email_key UNIQUE (email)
+);
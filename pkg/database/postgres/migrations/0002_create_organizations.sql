@@ -0,0 +1,18 @@
+CREATE TABLE organizations (
+    id          CHAR(24) PRIMARY KEY,
+    name        TEXT     NOT NULL,
+    description TEXT     NOT NULL DEFAULT '',
+    version     BIGINT   NOT NULL DEFAULT 1,
+    CONSTRAINT organizations_name_key UNIQUE (name)
+);
+
+CREATE TABLE organization_members (
+    organization_id CHAR(24) NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    position        INTEGER  NOT NULL,
+    name            TEXT     NOT NULL,
+    email           TEXT     NOT NULL,
+    access_level    INTEGER  NOT NULL,
+    PRIMARY KEY (organization_id, email)
+);
+
+CREATE INDEX organization_members_email_idx ON organization_members (email);
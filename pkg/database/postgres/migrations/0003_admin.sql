@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN platform_admin BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN suspended BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Force-deleted organizations are kept here until they are restored
+CREATE TABLE deleted_organizations (
+    id          CHAR(24)  PRIMARY KEY,
+    name        TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    version     BIGINT    NOT NULL,
+    deleted_at  TIMESTAMP NOT NULL
+);
+
+CREATE TABLE deleted_organization_members (
+    organization_id CHAR(24) NOT NULL REFERENCES deleted_organizations (id) ON DELETE CASCADE,
+    position        INTEGER  NOT NULL,
+    name            TEXT     NOT NULL,
+    email           TEXT     NOT NULL,
+    access_level    INTEGER  NOT NULL,
+    PRIMARY KEY (organization_id, email)
+);
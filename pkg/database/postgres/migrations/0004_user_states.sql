@@ -0,0 +1,4 @@
+-- Account states replace the suspended flag
+ALTER TABLE users ADD COLUMN state TEXT NOT NULL DEFAULT 'active';
+UPDATE users SET state = 'suspended' WHERE suspended;
+ALTER TABLE users DROP COLUMN suspended;
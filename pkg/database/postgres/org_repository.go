@@ -0,0 +1,517 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+var _ repository.OrganizationRepository = (*OrganizationRepository)(nil)
+
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func insertMembers(ctx context.Context, q querier, organizationID string, members []models.OrganizationMember) error {
+	for i, member := range members {
+		_, err := q.ExecContext(ctx, `INSERT INTO organization_members (organization_id, position, name, email, access_level)
+			VALUES ($1, $2, $3, $4, $5)`, organizationID, i+1, member.Name, member.Email, member.AccessLevel)
+		if err != nil {
+			return fmt.Errorf("failed to store organization member: %w", err)
+		}
+	}
+	return nil
+}
+
+// getOrganization returns nil, nil when the organization does not exist
+func getOrganization(ctx context.Context, q querier, where string, arg interface{}) (*models.Organization, error) {
+	var organization models.Organization
+	var id string
+	err := q.QueryRowContext(ctx, `SELECT id, name, description, version FROM organizations WHERE `+where+` = $1`, arg).
+		Scan(&id, &organization.Name, &organization.Description, &organization.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Organization not found
+		}
+		return nil, fmt.Errorf("failed to retrieve organization: %w", err)
+	}
+	organization.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organization: %w", err)
+	}
+
+	organization.OrganizationMembers, err = getMembers(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func getMembers(ctx context.Context, q querier, organizationID string) ([]models.OrganizationMember, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, email, access_level FROM organization_members
+		WHERE organization_id = $1 ORDER BY position`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organization members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.OrganizationMember
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.Name, &member.Email, &member.AccessLevel); err != nil {
+			return nil, fmt.Errorf("failed to retrieve organization members: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve organization members: %w", err)
+	}
+	return members, nil
+}
+
+// writeConflict tells apart a stale version from a missing organization after a
+// conditional write matched nothing
+func writeConflict(ctx context.Context, q querier, id string) error {
+	var count int
+	err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM organizations WHERE id = $1`, id).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check organization: %w", err)
+	}
+	if count == 0 {
+		return repository.ErrOrganizationNotFound
+	}
+	return repository.ErrVersionMismatch
+}
+
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (string, error) {
+	id := org.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Every organization starts at version 1
+	_, err = tx.ExecContext(ctx, `INSERT INTO organizations (id, name, description, version) VALUES ($1, $2, $3, 1)`,
+		id.Hex(), org.Name, org.Description)
+	if err != nil {
+		// The unique constraint on name is the source of truth
+		if uniqueViolation(err) {
+			return "", repository.ErrOrganizationNameExists
+		}
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+	if err := insertMembers(ctx, tx, id.Hex(), org.OrganizationMembers); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	org.ID = id
+	org.Version = 1
+	return id.Hex(), nil
+}
+
+func (r *OrganizationRepository) GetOrganizationByName(ctx context.Context, name string) (*models.Organization, error) {
+	return getOrganization(ctx, r.db, "name", name)
+}
+
+// UpdateOrganization updates the organization only if it is still at the expected
+// version, and returns the updated document
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (*models.Organization, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE organizations SET name = $1, description = $2, version = version + 1
+		WHERE id = $3 AND version = $4`, org.Name, org.Description, objID.Hex(), version)
+	if err != nil {
+		if uniqueViolation(err) {
+			return nil, repository.ErrOrganizationNameExists
+		}
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	} else if affected == 0 {
+		return nil, writeConflict(ctx, tx, objID.Hex())
+	}
+
+	if len(org.OrganizationMembers) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = $1`, objID.Hex()); err != nil {
+			return nil, fmt.Errorf("failed to update organization: %w", err)
+		}
+		if err := insertMembers(ctx, tx, objID.Hex(), org.OrganizationMembers); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := getOrganization(ctx, tx, "id", objID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteOrganization deletes the organization only if it is still at the expected version
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Members are removed explicitly, SQLite does not enforce foreign keys by default
+	_, err = tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = $1
+		AND EXISTS (SELECT 1 FROM organizations WHERE id = $1 AND version = $2)`, objID.Hex(), version)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM organizations WHERE id = $1 AND version = $2`, objID.Hex(), version)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	} else if affected == 0 {
+		return writeConflict(ctx, tx, objID.Hex())
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) GetAllOrganizations(ctx context.Context) ([]models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organizations: %w", err)
+	}
+	return r.organizations(ctx, rows)
+}
+
+func (r *OrganizationRepository) GetOrganizationsByMember(ctx context.Context, email string) ([]models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT organization_id FROM organization_members WHERE email = $1 ORDER BY organization_id`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organizations: %w", err)
+	}
+	return r.organizations(ctx, rows)
+}
+
+// organizations loads the organizations whose IDs rows returns
+func (r *OrganizationRepository) organizations(ctx context.Context, rows *sql.Rows) ([]models.Organization, error) {
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode organizations: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve organizations: %w", err)
+	}
+
+	organizations := make([]models.Organization, 0, len(ids))
+	for _, id := range ids {
+		organization, err := getOrganization(ctx, r.db, "id", id)
+		if err != nil {
+			return nil, err
+		}
+		if organization != nil {
+			organizations = append(organizations, *organization)
+		}
+	}
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	return getOrganization(ctx, r.db, "id", objID.Hex())
+}
+
+func (r *OrganizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (int, error) {
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return -1, repository.ErrInvalidOrganizationID
+	}
+
+	var accessLevel int
+	err = r.db.QueryRowContext(ctx, `SELECT access_level FROM organization_members WHERE organization_id = $1 AND email = $2`,
+		objID.Hex(), email).Scan(&accessLevel)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil // Email not found, return access level -1
+		}
+		return -1, fmt.Errorf("failed to retrieve access level: %w", err)
+	}
+	return accessLevel, nil
+}
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) error {
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add member to organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Bumping the version first locks the organization row for the rest of the transaction
+	res, err := tx.ExecContext(ctx, `UPDATE organizations SET version = version + 1 WHERE id = $1`, objID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to add member to organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to add member to organization: %w", err)
+	} else if affected == 0 {
+		return repository.ErrOrganizationNotFound
+	}
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND email = $2`,
+		objID.Hex(), member.Email).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check existing member: %w", err)
+	}
+	if exists > 0 {
+		return repository.ErrMemberExists
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO organization_members (organization_id, position, name, email, access_level)
+		SELECT $1, COALESCE(MAX(position), 0) + 1, $2, $3, CAST($4 AS INTEGER) FROM organization_members WHERE organization_id = $1`,
+		objID.Hex(), member.Name, member.Email, member.AccessLevel)
+	if err != nil {
+		return fmt.Errorf("failed to add member to organization: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, email string) error {
+	objID, err := primitive.ObjectIDFromHex(organizationID)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Bumping the version first locks the organization row for the rest of the transaction
+	res, err := tx.ExecContext(ctx, `UPDATE organizations SET version = version + 1 WHERE id = $1`, objID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	} else if affected == 0 {
+		return repository.ErrOrganizationNotFound
+	}
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = $1 AND email = $2`, objID.Hex(), email)
+	if err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove member from organization: %w", err)
+	} else if affected == 0 {
+		return repository.ErrMemberNotFound
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) CountOrganizations(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM organizations`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count organizations: %w", err)
+	}
+	return count, nil
+}
+
+// ForceDeleteOrganization moves the organization and its members to the
+// deleted_organizations tables in one transaction
+func (r *OrganizationRepository) ForceDeleteOrganization(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO deleted_organizations (id, name, description, version, deleted_at)
+		SELECT id, name, description, version, $2 FROM organizations WHERE id = $1`, objID.Hex(), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to keep a copy of the organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	} else if affected == 0 {
+		return repository.ErrOrganizationNotFound
+	}
+
+	statements := []string{
+		`INSERT INTO deleted_organization_members (organization_id, position, name, email, access_level)
+			SELECT organization_id, position, name, email, access_level FROM organization_members WHERE organization_id = $1`,
+		// Members are removed explicitly, SQLite does not enforce foreign keys by default
+		`DELETE FROM organization_members WHERE organization_id = $1`,
+		`DELETE FROM organizations WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, objID.Hex()); err != nil {
+			return fmt.Errorf("failed to delete organization: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// RestoreOrganization moves the organization and its members back from the
+// deleted_organizations tables in one transaction
+func (r *OrganizationRepository) RestoreOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidOrganizationID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO organizations (id, name, description, version)
+		SELECT id, name, description, version + 1 FROM deleted_organizations WHERE id = $1`, objID.Hex())
+	if err != nil {
+		// Another organization took the name in the meantime
+		if uniqueViolation(err) {
+			return nil, repository.ErrOrganizationNameExists
+		}
+		return nil, fmt.Errorf("failed to restore organization: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to restore organization: %w", err)
+	} else if affected == 0 {
+		return nil, repository.ErrOrganizationNotFound
+	}
+
+	statements := []string{
+		`INSERT INTO organization_members (organization_id, position, name, email, access_level)
+			SELECT organization_id, position, name, email, access_level FROM deleted_organization_members WHERE organization_id = $1`,
+		`DELETE FROM deleted_organization_members WHERE organization_id = $1`,
+		`DELETE FROM deleted_organizations WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, objID.Hex()); err != nil {
+			return nil, fmt.Errorf("failed to restore organization: %w", err)
+		}
+	}
+
+	restored, err := getOrganization(ctx, tx, "id", objID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to restore organization: %w", err)
+	}
+	return restored, nil
+}
+
+func (r *OrganizationRepository) GetDeletedOrganizations(ctx context.Context) ([]models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, description, version, deleted_at FROM deleted_organizations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve deleted organizations: %w", err)
+	}
+
+	var organizations []models.Organization
+	for rows.Next() {
+		var organization models.Organization
+		var id string
+		var deletedAt time.Time
+		if err := rows.Scan(&id, &organization.Name, &organization.Description, &organization.Version, &deletedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode deleted organizations: %w", err)
+		}
+		if organization.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode deleted organizations: %w", err)
+		}
+		deletedAt = deletedAt.UTC()
+		organization.DeletedAt = &deletedAt
+		organizations = append(organizations, organization)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve deleted organizations: %w", err)
+	}
+
+	// Members are read after the rows are closed, a connection serves one query at a time
+	for i := range organizations {
+		members, err := r.db.QueryContext(ctx, `SELECT name, email, access_level FROM deleted_organization_members
+			WHERE organization_id = $1 ORDER BY position`, organizations[i].ID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve deleted organization members: %w", err)
+		}
+		for members.Next() {
+			var member models.OrganizationMember
+			if err := members.Scan(&member.Name, &member.Email, &member.AccessLevel); err != nil {
+				members.Close()
+				return nil, fmt.Errorf("failed to retrieve deleted organization members: %w", err)
+			}
+			organizations[i].OrganizationMembers = append(organizations[i].OrganizationMembers, member)
+		}
+		members.Close()
+		if err := members.Err(); err != nil {
+			return nil, fmt.Errorf("failed to retrieve deleted organization members: %w", err)
+		}
+	}
+	return organizations, nil
+}
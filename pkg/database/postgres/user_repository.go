@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	// Hash the password before storing it in the database
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	id := user.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	state := user.State
+	if state == "" {
+		state = models.UserActive
+	}
+
+	_, err = r.db.ExecContext(ctx, `INSERT INTO users (id, name, email, password, platform_admin, state) VALUES ($1, $2, $3, $4, $5, $6)`,
+		id.Hex(), user.Name, user.Email, string(hashedPassword), user.PlatformAdmin, state)
+	if err != nil {
+		// The unique constraint on email is the source of truth
+		if uniqueViolation(err) {
+			return repository.ErrEmailExists
+		}
+		return err
+	}
+
+	user.ID = id
+	user.Password = string(hashedPassword)
+	user.State = state
+	return nil
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, id string, user *models.User) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	// Hash the password before updating it in the database, if provided
+	if user.Password != "" {
+		var hashedPassword []byte
+		hashedPassword, err = bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		user.Password = string(hashedPassword)
+		_, err = r.db.ExecContext(ctx, `UPDATE users SET name = $1, email = $2, password = $3 WHERE id = $4`,
+			user.Name, user.Email, user.Password, objID.Hex())
+	} else {
+		_, err = r.db.ExecContext(ctx, `UPDATE users SET name = $1, email = $2 WHERE id = $3`, user.Name, user.Email, objID.Hex())
+	}
+	if err != nil {
+		if uniqueViolation(err) {
+			return repository.ErrEmailExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, objID.Hex())
+	return err
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser returns nil, nil when the row does not exist
+func scanUser(row scanner) (*models.User, error) {
+	var user models.User
+	var id string
+	err := row.Scan(&id, &user.Name, &user.Email, &user.Password, &user.PlatformAdmin, &user.State)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+	user.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrInvalidUserID
+	}
+
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT id, name, email, password, platform_admin, state FROM users WHERE id = $1`, objID.Hex()))
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT id, name, email, password, platform_admin, state FROM users WHERE email = $1`, email))
+}
+
+func (r *UserRepository) AuthenticateUser(ctx context.Context, email, password string) (*models.User, error) {
+	user, err := r.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	// Compare the provided password with the hashed password from the database
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return nil, repository.ErrInvalidPassword
+	}
+	if err := repository.StateError(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *UserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, email, password, platform_admin, state FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) SetState(ctx context.Context, id string, state string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repository.ErrInvalidUserID
+	}
+
+	_, err = r.db.ExecContext(ctx, `UPDATE users SET state = $1 WHERE id = $2`, state, objID.Hex())
+	return err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// Error kinds. Every domain error matches exactly one of them with errors.Is,
+// which is what callers should branch on unless they need the specific error.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrInvalidID          = errors.New("invalid ID")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// DomainError is a definite answer from the domain, as opposed to a failure of
+// the storage itself. Code is stable and machine readable, clients match on it
+// rather than on Message; the codes are listed in docs/errors.md.
+type DomainError struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Is matches the error's kind
+func (e *DomainError) Is(target error) bool {
+	return target == e.Kind
+}
+
+var (
+	// ErrEmailExists is returned when creating a user with an email that is already registered
+	ErrEmailExists = &DomainError{Kind: ErrConflict, Code: "email_exists", Message: "email already in use"}
+	// ErrOrganizationNameExists is returned when creating an organization with a taken name
+	ErrOrganizationNameExists = &DomainError{Kind: ErrConflict, Code: "organization_name_exists", Message: "organization name already exists"}
+	// ErrOrganizationNotFound is returned by writes that target a missing organization
+	ErrOrganizationNotFound = &DomainError{Kind: ErrNotFound, Code: "organization_not_found", Message: "organization not found"}
+	// ErrMemberExists is returned when adding a member whose email is already in the organization
+	ErrMemberExists = &DomainError{Kind: ErrConflict, Code: "member_exists", Message: "email already exists in the organization"}
+	// ErrMemberNotFound is returned when removing an email that is not a member of the organization
+	ErrMemberNotFound = &DomainError{Kind: ErrNotFound, Code: "member_not_found", Message: "member not found"}
+	// ErrVersionMismatch is returned when a write is conditioned on an organization
+	// version that is no longer current
+	ErrVersionMismatch = &DomainError{Kind: ErrPreconditionFailed, Code: "version_mismatch", Message: "organization has been modified"}
+	// ErrInvalidUserID is returned when a user ID is not a valid identifier
+	ErrInvalidUserID = &DomainError{Kind: ErrInvalidID, Code: "invalid_user_id", Message: "invalid user ID"}
+	// ErrInvalidOrganizationID is returned when an organization ID is not a valid identifier
+	ErrInvalidOrganizationID = &DomainError{Kind: ErrInvalidID, Code: "invalid_organization_id", Message: "invalid organization ID"}
+	// ErrUserNotFound is returned by AuthenticateUser for an unknown email. It shares
+	// its code and message with ErrInvalidPassword so responses do not reveal
+	// which emails are registered.
+	ErrUserNotFound = &DomainError{Kind: ErrUnauthenticated, Code: "invalid_credentials", Message: "invalid email or password"}
+	// ErrInvalidPassword is returned by AuthenticateUser when the password does not match
+	ErrInvalidPassword = &DomainError{Kind: ErrUnauthenticated, Code: "invalid_credentials", Message: "invalid email or password"}
+	// ErrUnknownUser is returned when the user a valid token was issued to has been deleted
+	ErrUnknownUser = &DomainError{Kind: ErrUnauthenticated, Code: "unknown_user", Message: "the user of this token no longer exists"}
+	// ErrUserSuspended is returned for a suspended user with the right password or
+	// a valid token
+	ErrUserSuspended = &DomainError{Kind: ErrForbidden, Code: "user_suspended", Message: "this account is suspended"}
+	// ErrUserDeactivated is returned for a deactivated user with the right password
+	// or a valid token
+	ErrUserDeactivated = &DomainError{Kind: ErrForbidden, Code: "user_deactivated", Message: "this account is deactivated"}
+)
+
+// StateError returns the error for a user whose account state forbids signing
+// in or using a token, nil for active users
+func StateError(user *models.User) error {
+	switch {
+	case user.Active():
+		return nil
+	case user.State == models.UserDeactivated:
+		return ErrUserDeactivated
+	}
+	return ErrUserSuspended
+}
+
+// IsDomainError reports whether err is a definite answer from the backend, which is
+// kept even if the deadline passed while it was being returned
+func IsDomainError(err error) bool {
+	var domainErr *DomainError
+	return errors.As(err, &domainErr)
+}
@@ -0,0 +1,72 @@
+// Package repository defines the storage interfaces the controllers depend on.
+// Every backend (MongoDB, in-memory) implements them with the same semantics,
+// which are pinned down by the conformance suite in repotest.
+package repository
+
+import (
+	"context"
+
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// UserRepository stores users. Lookups return a nil user and a nil error when
+// the user does not exist.
+type UserRepository interface {
+	// CreateUser hashes the password, stores the user and sets its ID
+	CreateUser(ctx context.Context, user *models.User) error
+	// UpdateUser replaces name and email, and the password when one is provided.
+	// It returns ErrEmailExists if another user has the email.
+	UpdateUser(ctx context.Context, id string, user *models.User) error
+	DeleteUser(ctx context.Context, id string) error
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// AuthenticateUser returns the user if the password matches the stored hash
+	AuthenticateUser(ctx context.Context, email, password string) (*models.User, error)
+	// CountUsers returns the number of registered users
+	CountUsers(ctx context.Context) (int64, error)
+	// GetAllUsers returns every user, oldest first
+	GetAllUsers(ctx context.Context) ([]models.User, error)
+	// SetState moves the user to one of the models.User* account states.
+	// AuthenticateUser refuses users that are not active, see StateError.
+	SetState(ctx context.Context, id string, state string) error
+}
+
+// OrganizationRepository stores organizations and their members. Lookups return a
+// nil organization and a nil error when the organization does not exist.
+type OrganizationRepository interface {
+	// CreateOrganization stores the organization at version 1 and returns its ID
+	CreateOrganization(ctx context.Context, org *models.Organization) (string, error)
+	GetOrganizationByName(ctx context.Context, name string) (*models.Organization, error)
+	// UpdateOrganization writes the organization if it is still at the given version
+	// and returns the updated document. It returns ErrOrganizationNameExists if
+	// another organization has the name.
+	UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (*models.Organization, error)
+	// DeleteOrganization deletes the organization if it is still at the given version
+	DeleteOrganization(ctx context.Context, id string, version int64) error
+	// GetAllOrganizations returns every organization, oldest first
+	GetAllOrganizations(ctx context.Context) ([]models.Organization, error)
+	// GetOrganizationsByMember returns the organizations the email is a member of,
+	// oldest first
+	GetOrganizationsByMember(ctx context.Context, email string) ([]models.Organization, error)
+	GetOrganizationByID(ctx context.Context, id string) (*models.Organization, error)
+	// GetAccessLevelByEmail returns the member's access level, or -1 if the email
+	// is not a member of the organization
+	GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (int, error)
+	// AddMember appends a member, rejecting emails already in the organization
+	AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) error
+	// RemoveMember removes the member with the email, returning ErrMemberNotFound
+	// if there is none
+	RemoveMember(ctx context.Context, organizationID, email string) error
+	// CountOrganizations returns the number of organizations
+	CountOrganizations(ctx context.Context) (int64, error)
+	// ForceDeleteOrganization deletes the organization at any version. Unlike
+	// DeleteOrganization it keeps a copy that RestoreOrganization brings back.
+	ForceDeleteOrganization(ctx context.Context, id string) error
+	// RestoreOrganization brings back a force-deleted organization at its next
+	// version. It returns ErrOrganizationNotFound if there is no such copy and
+	// ErrOrganizationNameExists if the name was taken in the meantime.
+	RestoreOrganization(ctx context.Context, id string) (*models.Organization, error)
+	// GetDeletedOrganizations returns the force-deleted organizations with their
+	// DeletedAt, oldest first
+	GetDeletedOrganizations(ctx context.Context) ([]models.Organization, error)
+}
@@ -0,0 +1,500 @@
+// Package repotest is a conformance suite for implementations of the repository
+// interfaces. tests/unit calls TestUserRepository and TestOrganizationRepository
+// for every backend with a constructor returning an empty repository:
+//
+//	func TestMemoryRepositories(t *testing.T) {
+//		repotest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+//			return memory.NewUserRepository()
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// missingID is a well-formed ID that no backend will ever have generated
+var missingID = primitive.NewObjectID().Hex()
+
+var ctx = context.Background()
+
+// TestUserRepository runs the user repository conformance suite. newRepo must
+// return an empty repository for every call.
+func TestUserRepository(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		user := &models.User{Name: "Ada", Email: "ada@example.com", Password: "secret"}
+		if err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if user.ID.IsZero() {
+			t.Fatal("CreateUser did not set the user ID")
+		}
+		if user.Password == "secret" {
+			t.Fatal("CreateUser stored the plain text password")
+		}
+
+		got, err := repo.GetUser(ctx, user.ID.Hex())
+		if err != nil || got == nil {
+			t.Fatalf("GetUser = %v, %v", got, err)
+		}
+		if got.Name != "Ada" || got.Email != "ada@example.com" {
+			t.Fatalf("GetUser returned %+v", got)
+		}
+
+		got, err = repo.GetUserByEmail(ctx, "ada@example.com")
+		if err != nil || got == nil || got.ID != user.ID {
+			t.Fatalf("GetUserByEmail = %v, %v", got, err)
+		}
+
+		if count, err := repo.CountUsers(ctx); err != nil || count != 1 {
+			t.Fatalf("CountUsers = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.CreateUser(ctx, &models.User{Name: "A", Email: "dup@example.com", Password: "x"}); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		err := repo.CreateUser(ctx, &models.User{Name: "B", Email: "dup@example.com", Password: "y"})
+		if !errors.Is(err, repository.ErrEmailExists) {
+			t.Fatalf("CreateUser with duplicate email = %v, want ErrEmailExists", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		if got, err := repo.GetUser(ctx, missingID); got != nil || err != nil {
+			t.Fatalf("GetUser(missing) = %v, %v, want nil, nil", got, err)
+		}
+		if got, err := repo.GetUserByEmail(ctx, "nobody@example.com"); got != nil || err != nil {
+			t.Fatalf("GetUserByEmail(missing) = %v, %v, want nil, nil", got, err)
+		}
+		if _, err := repo.GetUser(ctx, "not-an-id"); !errors.Is(err, repository.ErrInvalidUserID) {
+			t.Fatalf("GetUser(invalid) = %v, want ErrInvalidUserID", err)
+		}
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.CreateUser(ctx, &models.User{Name: "A", Email: "auth@example.com", Password: "right"}); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if user, err := repo.AuthenticateUser(ctx, "auth@example.com", "right"); err != nil || user == nil {
+			t.Fatalf("AuthenticateUser(valid) = %v, %v", user, err)
+		}
+		if _, err := repo.AuthenticateUser(ctx, "auth@example.com", "wrong"); !errors.Is(err, repository.ErrInvalidPassword) {
+			t.Fatalf("AuthenticateUser(wrong password) = %v, want ErrInvalidPassword", err)
+		}
+		if _, err := repo.AuthenticateUser(ctx, "nobody@example.com", "right"); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Fatalf("AuthenticateUser(unknown) = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("UpdateKeepsPasswordWhenEmpty", func(t *testing.T) {
+		repo := newRepo(t)
+		user := &models.User{Name: "A", Email: "upd@example.com", Password: "pw"}
+		if err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if err := repo.UpdateUser(ctx, user.ID.Hex(), &models.User{Name: "B", Email: "upd@example.com"}); err != nil {
+			t.Fatalf("UpdateUser: %v", err)
+		}
+		got, _ := repo.GetUser(ctx, user.ID.Hex())
+		if got == nil || got.Name != "B" {
+			t.Fatalf("GetUser after update = %+v", got)
+		}
+		if _, err := repo.AuthenticateUser(ctx, "upd@example.com", "pw"); err != nil {
+			t.Fatalf("password changed by update without password: %v", err)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		repo := newRepo(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := repo.CreateUser(canceled, &models.User{Name: "A", Email: "c@example.com", Password: "pw"}); err == nil {
+			t.Fatal("CreateUser with a canceled context succeeded")
+		}
+	})
+
+	t.Run("PlatformAdminAndStates", func(t *testing.T) {
+		repo := newRepo(t)
+		admin := &models.User{Name: "Admin", Email: "admin@example.com", Password: "pw", PlatformAdmin: true}
+		user := &models.User{Name: "User", Email: "user@example.com", Password: "pw"}
+		for _, u := range []*models.User{admin, user} {
+			if err := repo.CreateUser(ctx, u); err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+		}
+
+		users, err := repo.GetAllUsers(ctx)
+		if err != nil || len(users) != 2 {
+			t.Fatalf("GetAllUsers = %d users, %v, want 2", len(users), err)
+		}
+		if users[0].ID != admin.ID || !users[0].PlatformAdmin || users[1].PlatformAdmin {
+			t.Fatalf("GetAllUsers = %+v, want the admin first", users)
+		}
+		// New users are active
+		if users[1].State != models.UserActive {
+			t.Fatalf("state of a new user = %q, want %q", users[1].State, models.UserActive)
+		}
+
+		for state, want := range map[string]error{
+			models.UserSuspended:   repository.ErrUserSuspended,
+			models.UserDeactivated: repository.ErrUserDeactivated,
+		} {
+			if err := repo.SetState(ctx, user.ID.Hex(), state); err != nil {
+				t.Fatalf("SetState(%s): %v", state, err)
+			}
+			if got, _ := repo.GetUser(ctx, user.ID.Hex()); got == nil || got.State != state || got.Active() {
+				t.Fatalf("GetUser after SetState(%s) = %+v", state, got)
+			}
+			// The password is checked first, so the state is only revealed to the user
+			if _, err := repo.AuthenticateUser(ctx, "user@example.com", "wrong"); !errors.Is(err, repository.ErrInvalidPassword) {
+				t.Fatalf("AuthenticateUser(%s, wrong password) = %v, want ErrInvalidPassword", state, err)
+			}
+			if _, err := repo.AuthenticateUser(ctx, "user@example.com", "pw"); !errors.Is(err, want) {
+				t.Fatalf("AuthenticateUser(%s) = %v, want %v", state, err, want)
+			}
+		}
+
+		if err := repo.SetState(ctx, user.ID.Hex(), models.UserActive); err != nil {
+			t.Fatalf("SetState(active): %v", err)
+		}
+		if _, err := repo.AuthenticateUser(ctx, "user@example.com", "pw"); err != nil {
+			t.Fatalf("AuthenticateUser after reactivation = %v", err)
+		}
+		if err := repo.SetState(ctx, "not-an-id", models.UserSuspended); !errors.Is(err, repository.ErrInvalidUserID) {
+			t.Fatalf("SetState(invalid) = %v, want ErrInvalidUserID", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		user := &models.User{Name: "A", Email: "del@example.com", Password: "pw"}
+		if err := repo.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if err := repo.DeleteUser(ctx, user.ID.Hex()); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		if got, err := repo.GetUser(ctx, user.ID.Hex()); got != nil || err != nil {
+			t.Fatalf("GetUser after delete = %v, %v", got, err)
+		}
+	})
+}
+
+// TestOrganizationRepository runs the organization repository conformance suite.
+// newRepo must return an empty repository for every call.
+func TestOrganizationRepository(t *testing.T, newRepo func(t *testing.T) repository.OrganizationRepository) {
+	owner := models.OrganizationMember{Name: "Owner", Email: "owner@example.com", AccessLevel: 1}
+
+	create := func(t *testing.T, repo repository.OrganizationRepository, name string) string {
+		t.Helper()
+		org := &models.Organization{
+			Name:                name,
+			Description:         "description",
+			OrganizationMembers: []models.OrganizationMember{owner},
+		}
+		id, err := repo.CreateOrganization(ctx, org)
+		if err != nil {
+			t.Fatalf("CreateOrganization: %v", err)
+		}
+		return id
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "acme")
+
+		org, err := repo.GetOrganizationByID(ctx, id)
+		if err != nil || org == nil {
+			t.Fatalf("GetOrganizationByID = %v, %v", org, err)
+		}
+		if org.Name != "acme" || org.Version != 1 || len(org.OrganizationMembers) != 1 {
+			t.Fatalf("GetOrganizationByID returned %+v", org)
+		}
+		if byName, err := repo.GetOrganizationByName(ctx, "acme"); err != nil || byName == nil || byName.ID.Hex() != id {
+			t.Fatalf("GetOrganizationByName = %+v, %v", byName, err)
+		}
+
+		all, err := repo.GetAllOrganizations(ctx)
+		if err != nil || len(all) != 1 {
+			t.Fatalf("GetAllOrganizations = %v, %v", all, err)
+		}
+		if count, err := repo.CountOrganizations(ctx); err != nil || count != 1 {
+			t.Fatalf("CountOrganizations = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "taken")
+		_, err := repo.CreateOrganization(ctx, &models.Organization{Name: "taken"})
+		if !errors.Is(err, repository.ErrOrganizationNameExists) {
+			t.Fatalf("CreateOrganization with duplicate name = %v, want ErrOrganizationNameExists", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		if org, err := repo.GetOrganizationByID(ctx, missingID); org != nil || err != nil {
+			t.Fatalf("GetOrganizationByID(missing) = %v, %v, want nil, nil", org, err)
+		}
+		if org, err := repo.GetOrganizationByName(ctx, "missing"); org != nil || err != nil {
+			t.Fatalf("GetOrganizationByName(missing) = %v, %v, want nil, nil", org, err)
+		}
+		if _, err := repo.GetOrganizationByID(ctx, "not-an-id"); !errors.Is(err, repository.ErrInvalidOrganizationID) {
+			t.Fatalf("GetOrganizationByID(invalid) = %v, want ErrInvalidOrganizationID", err)
+		}
+		if _, err := repo.UpdateOrganization(ctx, missingID, &models.Organization{Name: "x"}, 1); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("UpdateOrganization(missing) = %v, want ErrOrganizationNotFound", err)
+		}
+		if err := repo.DeleteOrganization(ctx, missingID, 1); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("DeleteOrganization(missing) = %v, want ErrOrganizationNotFound", err)
+		}
+		if err := repo.AddMember(ctx, missingID, &models.OrganizationMember{Email: "a@example.com"}); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("AddMember(missing) = %v, want ErrOrganizationNotFound", err)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		repo := newRepo(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := repo.CreateOrganization(canceled, &models.Organization{Name: "canceled"}); err == nil {
+			t.Fatal("CreateOrganization with a canceled context succeeded")
+		}
+	})
+
+	// Lookups report driver errors instead of passing them off as not found
+	t.Run("Timeout", func(t *testing.T) {
+		repo := newRepo(t)
+		create(t, repo, "slow")
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+		if org, err := repo.GetOrganizationByName(expired, "slow"); org != nil || err == nil {
+			t.Fatalf("GetOrganizationByName past its deadline = %v, %v, want an error", org, err)
+		}
+		if org, err := repo.GetOrganizationByID(expired, missingID); org != nil || err == nil {
+			t.Fatalf("GetOrganizationByID past its deadline = %v, %v, want an error", org, err)
+		}
+
+		// The timeout wrapper reports the error as ErrTimeout
+		timeouts := repository.Timeouts{Operations: map[string]time.Duration{"GetOrganizationByName": time.Nanosecond}}
+		wrapped := repository.WithOrganizationTimeouts(repo, timeouts)
+		if _, err := wrapped.GetOrganizationByName(ctx, "slow"); !errors.Is(err, repository.ErrTimeout) {
+			t.Fatalf("GetOrganizationByName with a 1ns timeout = %v, want ErrTimeout", err)
+		}
+	})
+
+	t.Run("UpdateIncrementsVersion", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "versioned")
+
+		updated, err := repo.UpdateOrganization(ctx, id, &models.Organization{Name: "renamed", Description: "new"}, 1)
+		if err != nil {
+			t.Fatalf("UpdateOrganization: %v", err)
+		}
+		if updated.Version != 2 || updated.Name != "renamed" || len(updated.OrganizationMembers) != 1 {
+			t.Fatalf("UpdateOrganization returned %+v", updated)
+		}
+
+		_, err = repo.UpdateOrganization(ctx, id, &models.Organization{Name: "stale"}, 1)
+		if !errors.Is(err, repository.ErrVersionMismatch) {
+			t.Fatalf("UpdateOrganization with stale version = %v, want ErrVersionMismatch", err)
+		}
+		if err := repo.DeleteOrganization(ctx, id, 1); !errors.Is(err, repository.ErrVersionMismatch) {
+			t.Fatalf("DeleteOrganization with stale version = %v, want ErrVersionMismatch", err)
+		}
+		if err := repo.DeleteOrganization(ctx, id, 2); err != nil {
+			t.Fatalf("DeleteOrganization: %v", err)
+		}
+		if org, _ := repo.GetOrganizationByID(ctx, id); org != nil {
+			t.Fatalf("organization still present after delete: %+v", org)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "members")
+
+		member := &models.OrganizationMember{Name: "Member", Email: "member@example.com", AccessLevel: 0}
+		if err := repo.AddMember(ctx, id, member); err != nil {
+			t.Fatalf("AddMember: %v", err)
+		}
+		if err := repo.AddMember(ctx, id, member); !errors.Is(err, repository.ErrMemberExists) {
+			t.Fatalf("AddMember twice = %v, want ErrMemberExists", err)
+		}
+
+		if level, err := repo.GetAccessLevelByEmail(ctx, id, owner.Email); err != nil || level != 1 {
+			t.Fatalf("GetAccessLevelByEmail(owner) = %d, %v, want 1", level, err)
+		}
+		if level, err := repo.GetAccessLevelByEmail(ctx, id, member.Email); err != nil || level != 0 {
+			t.Fatalf("GetAccessLevelByEmail(member) = %d, %v, want 0", level, err)
+		}
+		if level, err := repo.GetAccessLevelByEmail(ctx, id, "stranger@example.com"); err != nil || level != -1 {
+			t.Fatalf("GetAccessLevelByEmail(stranger) = %d, %v, want -1", level, err)
+		}
+
+		org, _ := repo.GetOrganizationByID(ctx, id)
+		if org == nil || org.Version != 2 {
+			t.Fatalf("AddMember did not increment the version: %+v", org)
+		}
+
+		other := create(t, repo, "other")
+		memberOf, err := repo.GetOrganizationsByMember(ctx, member.Email)
+		if err != nil || len(memberOf) != 1 || memberOf[0].ID.Hex() != id {
+			t.Fatalf("GetOrganizationsByMember(member) = %+v, %v, want only %s", memberOf, err, id)
+		}
+		if ownerOf, err := repo.GetOrganizationsByMember(ctx, owner.Email); err != nil || len(ownerOf) != 2 || ownerOf[1].ID.Hex() != other {
+			t.Fatalf("GetOrganizationsByMember(owner) = %+v, %v, want both oldest first", ownerOf, err)
+		}
+
+		if err := repo.RemoveMember(ctx, id, member.Email); err != nil {
+			t.Fatalf("RemoveMember: %v", err)
+		}
+		if err := repo.RemoveMember(ctx, id, member.Email); !errors.Is(err, repository.ErrMemberNotFound) {
+			t.Fatalf("RemoveMember twice = %v, want ErrMemberNotFound", err)
+		}
+		if err := repo.RemoveMember(ctx, missingID, member.Email); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("RemoveMember(missing) = %v, want ErrOrganizationNotFound", err)
+		}
+		org, _ = repo.GetOrganizationByID(ctx, id)
+		if org == nil || org.Version != 3 || len(org.OrganizationMembers) != 1 {
+			t.Fatalf("RemoveMember did not remove the member and increment the version: %+v", org)
+		}
+		if memberOf, err := repo.GetOrganizationsByMember(ctx, member.Email); err != nil || len(memberOf) != 0 {
+			t.Fatalf("GetOrganizationsByMember(removed) = %+v, %v, want none", memberOf, err)
+		}
+	})
+
+	t.Run("ForceDeleteAndRestore", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "acme")
+		other := create(t, repo, "other")
+		if err := repo.AddMember(ctx, id, &models.OrganizationMember{Name: "M", Email: "m@example.com"}); err != nil {
+			t.Fatalf("AddMember: %v", err)
+		}
+
+		if err := repo.ForceDeleteOrganization(ctx, id); err != nil {
+			t.Fatalf("ForceDeleteOrganization: %v", err)
+		}
+		if org, err := repo.GetOrganizationByID(ctx, id); org != nil || err != nil {
+			t.Fatalf("GetOrganizationByID after force delete = %v, %v, want nil, nil", org, err)
+		}
+		if orgs, err := repo.GetAllOrganizations(ctx); err != nil || len(orgs) != 1 || orgs[0].ID.Hex() != other {
+			t.Fatalf("GetAllOrganizations after force delete = %+v, %v, want only %s", orgs, err, other)
+		}
+		if err := repo.ForceDeleteOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("ForceDeleteOrganization twice = %v, want ErrOrganizationNotFound", err)
+		}
+
+		deleted, err := repo.GetDeletedOrganizations(ctx)
+		if err != nil || len(deleted) != 1 {
+			t.Fatalf("GetDeletedOrganizations = %+v, %v, want one", deleted, err)
+		}
+		if deleted[0].ID.Hex() != id || deleted[0].DeletedAt == nil || len(deleted[0].OrganizationMembers) != 2 {
+			t.Fatalf("GetDeletedOrganizations = %+v, want %s with its members and DeletedAt", deleted[0], id)
+		}
+
+		restored, err := repo.RestoreOrganization(ctx, id)
+		if err != nil {
+			t.Fatalf("RestoreOrganization: %v", err)
+		}
+		// Created at 1, AddMember made it 2, restoring makes it 3
+		if restored.Version != 3 || restored.DeletedAt != nil || len(restored.OrganizationMembers) != 2 {
+			t.Fatalf("RestoreOrganization = %+v, want version 3 with both members", restored)
+		}
+		if org, err := repo.GetOrganizationByID(ctx, id); err != nil || org == nil || org.Name != "acme" {
+			t.Fatalf("GetOrganizationByID after restore = %+v, %v", org, err)
+		}
+		if deleted, err := repo.GetDeletedOrganizations(ctx); err != nil || len(deleted) != 0 {
+			t.Fatalf("GetDeletedOrganizations after restore = %+v, %v, want none", deleted, err)
+		}
+		if _, err := repo.RestoreOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("RestoreOrganization twice = %v, want ErrOrganizationNotFound", err)
+		}
+
+		// A name taken while the organization was deleted blocks the restore
+		if err := repo.ForceDeleteOrganization(ctx, id); err != nil {
+			t.Fatalf("ForceDeleteOrganization: %v", err)
+		}
+		create(t, repo, "acme")
+		if _, err := repo.RestoreOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNameExists) {
+			t.Fatalf("RestoreOrganization with a taken name = %v, want ErrOrganizationNameExists", err)
+		}
+		if deleted, err := repo.GetDeletedOrganizations(ctx); err != nil || len(deleted) != 1 {
+			t.Fatalf("GetDeletedOrganizations after a failed restore = %+v, %v, want it kept", deleted, err)
+		}
+
+		if err := repo.ForceDeleteOrganization(ctx, "not-an-id"); !errors.Is(err, repository.ErrInvalidOrganizationID) {
+			t.Fatalf("ForceDeleteOrganization(invalid) = %v, want ErrInvalidOrganizationID", err)
+		}
+		if _, err := repo.RestoreOrganization(ctx, missingID); !errors.Is(err, repository.ErrOrganizationNotFound) {
+			t.Fatalf("RestoreOrganization(missing) = %v, want ErrOrganizationNotFound", err)
+		}
+	})
+
+	t.Run("ConcurrentUpdates", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "contended")
+
+		// Every writer holds version 1, exactly one of them may win
+		const writers = 8
+		var wg sync.WaitGroup
+		var succeeded int32
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.UpdateOrganization(ctx, id, &models.Organization{Name: "contended"}, 1)
+				if err == nil {
+					atomic.AddInt32(&succeeded, 1)
+				} else if !errors.Is(err, repository.ErrVersionMismatch) {
+					t.Errorf("UpdateOrganization: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if succeeded != 1 {
+			t.Fatalf("%d concurrent updates succeeded, want 1", succeeded)
+		}
+	})
+
+	t.Run("ConcurrentMembers", func(t *testing.T) {
+		repo := newRepo(t)
+		id := create(t, repo, "crowded")
+
+		const members = 8
+		var wg sync.WaitGroup
+		for i := 0; i < members; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				member := &models.OrganizationMember{Email: primitive.NewObjectID().Hex() + "@example.com"}
+				if err := repo.AddMember(ctx, id, member); err != nil {
+					t.Errorf("AddMember: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		org, _ := repo.GetOrganizationByID(ctx, id)
+		if org == nil || len(org.OrganizationMembers) != members+1 || org.Version != members+1 {
+			t.Fatalf("after concurrent AddMember got %+v", org)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Go-api/pkg/database/mongodb/models"
+)
+
+var (
+	// ErrTimeout is returned when a repository operation exceeds its own deadline
+	ErrTimeout = errors.New("database operation timed out")
+	// ErrCanceled is returned when the caller's context ended before the operation
+	// finished, because the client went away or the request deadline passed
+	ErrCanceled = errors.New("database operation canceled")
+)
+
+// Timeouts configures the deadline of each repository operation
+type Timeouts struct {
+	// Default applies to every operation without an override, zero means no deadline
+	Default time.Duration `yaml:"default"`
+	// Operations overrides the deadline by method name, e.g. AuthenticateUser
+	Operations map[string]time.Duration `yaml:"operations"`
+}
+
+// For returns the deadline of the named operation
+func (t Timeouts) For(operation string) time.Duration {
+	if d, ok := t.Operations[operation]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// run calls fn with a context bounded by the operation's deadline and translates
+// context errors, which every driver reports differently, into ErrTimeout and
+// ErrCanceled
+func (t Timeouts) run(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", operation, ErrCanceled)
+	}
+
+	opCtx := ctx
+	if d := t.For(operation); d > 0 {
+		var cancel context.CancelFunc
+		opCtx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	err := fn(opCtx)
+	if err == nil || opCtx.Err() == nil || IsDomainError(err) {
+		return err
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", operation, ErrCanceled)
+	}
+	return fmt.Errorf("%s: %w", operation, ErrTimeout)
+}
+
+type timeoutUserRepository struct {
+	next     UserRepository
+	timeouts Timeouts
+}
+
+// WithUserTimeouts wraps a user repository so every operation runs under its
+// configured deadline
+func WithUserTimeouts(next UserRepository, timeouts Timeouts) UserRepository {
+	return &timeoutUserRepository{next: next, timeouts: timeouts}
+}
+
+func (r *timeoutUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	return r.timeouts.run(ctx, "CreateUser", func(ctx context.Context) error {
+		return r.next.CreateUser(ctx, user)
+	})
+}
+
+func (r *timeoutUserRepository) UpdateUser(ctx context.Context, id string, user *models.User) error {
+	return r.timeouts.run(ctx, "UpdateUser", func(ctx context.Context) error {
+		return r.next.UpdateUser(ctx, id, user)
+	})
+}
+
+func (r *timeoutUserRepository) DeleteUser(ctx context.Context, id string) error {
+	return r.timeouts.run(ctx, "DeleteUser", func(ctx context.Context) error {
+		return r.next.DeleteUser(ctx, id)
+	})
+}
+
+func (r *timeoutUserRepository) GetUser(ctx context.Context, id string) (user *models.User, err error) {
+	err = r.timeouts.run(ctx, "GetUser", func(ctx context.Context) error {
+		user, err = r.next.GetUser(ctx, id)
+		return err
+	})
+	return user, err
+}
+
+func (r *timeoutUserRepository) GetUserByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	err = r.timeouts.run(ctx, "GetUserByEmail", func(ctx context.Context) error {
+		user, err = r.next.GetUserByEmail(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+func (r *timeoutUserRepository) AuthenticateUser(ctx context.Context, email, password string) (user *models.User, err error) {
+	err = r.timeouts.run(ctx, "AuthenticateUser", func(ctx context.Context) error {
+		user, err = r.next.AuthenticateUser(ctx, email, password)
+		return err
+	})
+	return user, err
+}
+
+func (r *timeoutUserRepository) CountUsers(ctx context.Context) (count int64, err error) {
+	err = r.timeouts.run(ctx, "CountUsers", func(ctx context.Context) error {
+		count, err = r.next.CountUsers(ctx)
+		return err
+	})
+	return count, err
+}
+
+func (r *timeoutUserRepository) GetAllUsers(ctx context.Context) (users []models.User, err error) {
+	err = r.timeouts.run(ctx, "GetAllUsers", func(ctx context.Context) error {
+		users, err = r.next.GetAllUsers(ctx)
+		return err
+	})
+	return users, err
+}
+
+func (r *timeoutUserRepository) SetState(ctx context.Context, id string, state string) error {
+	return r.timeouts.run(ctx, "SetState", func(ctx context.Context) error {
+		return r.next.SetState(ctx, id, state)
+	})
+}
+
+type timeoutOrganizationRepository struct {
+	next     OrganizationRepository
+	timeouts Timeouts
+}
+
+// WithOrganizationTimeouts wraps an organization repository so every operation
+// runs under its configured deadline
+func WithOrganizationTimeouts(next OrganizationRepository, timeouts Timeouts) OrganizationRepository {
+	return &timeoutOrganizationRepository{next: next, timeouts: timeouts}
+}
+
+func (r *timeoutOrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (id string, err error) {
+	err = r.timeouts.run(ctx, "CreateOrganization", func(ctx context.Context) error {
+		id, err = r.next.CreateOrganization(ctx, org)
+		return err
+	})
+	return id, err
+}
+
+func (r *timeoutOrganizationRepository) GetOrganizationByName(ctx context.Context, name string) (org *models.Organization, err error) {
+	err = r.timeouts.run(ctx, "GetOrganizationByName", func(ctx context.Context) error {
+		org, err = r.next.GetOrganizationByName(ctx, name)
+		return err
+	})
+	return org, err
+}
+
+func (r *timeoutOrganizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (updated *models.Organization, err error) {
+	err = r.timeouts.run(ctx, "UpdateOrganization", func(ctx context.Context) error {
+		updated, err = r.next.UpdateOrganization(ctx, id, org, version)
+		return err
+	})
+	return updated, err
+}
+
+func (r *timeoutOrganizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) error {
+	return r.timeouts.run(ctx, "DeleteOrganization", func(ctx context.Context) error {
+		return r.next.DeleteOrganization(ctx, id, version)
+	})
+}
+
+func (r *timeoutOrganizationRepository) GetAllOrganizations(ctx context.Context) (orgs []models.Organization, err error) {
+	err = r.timeouts.run(ctx, "GetAllOrganizations", func(ctx context.Context) error {
+		orgs, err = r.next.GetAllOrganizations(ctx)
+		return err
+	})
+	return orgs, err
+}
+
+func (r *timeoutOrganizationRepository) GetOrganizationsByMember(ctx context.Context, email string) (orgs []models.Organization, err error) {
+	err = r.timeouts.run(ctx, "GetOrganizationsByMember", func(ctx context.Context) error {
+		orgs, err = r.next.GetOrganizationsByMember(ctx, email)
+		return err
+	})
+	return orgs, err
+}
+
+func (r *timeoutOrganizationRepository) GetOrganizationByID(ctx context.Context, id string) (org *models.Organization, err error) {
+	err = r.timeouts.run(ctx, "GetOrganizationByID", func(ctx context.Context) error {
+		org, err = r.next.GetOrganizationByID(ctx, id)
+		return err
+	})
+	return org, err
+}
+
+func (r *timeoutOrganizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (level int, err error) {
+	level = -1
+	err = r.timeouts.run(ctx, "GetAccessLevelByEmail", func(ctx context.Context) error {
+		level, err = r.next.GetAccessLevelByEmail(ctx, organizationID, email)
+		return err
+	})
+	return level, err
+}
+
+func (r *timeoutOrganizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) error {
+	return r.timeouts.run(ctx, "AddMember", func(ctx context.Context) error {
+		return r.next.AddMember(ctx, organizationID, member)
+	})
+}
+
+func (r *timeoutOrganizationRepository) RemoveMember(ctx context.Context, organizationID, email string) error {
+	return r.timeouts.run(ctx, "RemoveMember", func(ctx context.Context) error {
+		return r.next.RemoveMember(ctx, organizationID, email)
+	})
+}
+
+func (r *timeoutOrganizationRepository) CountOrganizations(ctx context.Context) (count int64, err error) {
+	err = r.timeouts.run(ctx, "CountOrganizations", func(ctx context.Context) error {
+		count, err = r.next.CountOrganizations(ctx)
+		return err
+	})
+	return count, err
+}
+
+func (r *timeoutOrganizationRepository) ForceDeleteOrganization(ctx context.Context, id string) error {
+	return r.timeouts.run(ctx, "ForceDeleteOrganization", func(ctx context.Context) error {
+		return r.next.ForceDeleteOrganization(ctx, id)
+	})
+}
+
+func (r *timeoutOrganizationRepository) RestoreOrganization(ctx context.Context, id string) (org *models.Organization, err error) {
+	err = r.timeouts.run(ctx, "RestoreOrganization", func(ctx context.Context) error {
+		org, err = r.next.RestoreOrganization(ctx, id)
+		return err
+	})
+	return org, err
+}
+
+func (r *timeoutOrganizationRepository) GetDeletedOrganizations(ctx context.Context) (orgs []models.Organization, err error) {
+	err = r.timeouts.run(ctx, "GetDeletedOrganizations", func(ctx context.Context) error {
+		orgs, err = r.next.GetDeletedOrganizations(ctx)
+		return err
+	})
+	return orgs, err
+}
@@ -0,0 +1,242 @@
+// Package store selects and opens the storage backend named in the database
+// section of the configuration.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+	database "Go-api/pkg/database/mongodb"
+	mongorepo "Go-api/pkg/database/mongodb/repository"
+	"Go-api/pkg/database/postgres"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/ratelimit"
+)
+
+// Supported values for database.driver
+const (
+	DriverMongoDB  = "mongodb"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Connection retry backoff, doubling from the initial delay up to the maximum
+const (
+	initialRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+)
+
+// backend is the driver specific part of a Store
+type backend interface {
+	Ping(ctx context.Context) error
+	// Pending returns the number of migrations not applied yet
+	Pending(ctx context.Context) (int, error)
+	Migrate(ctx context.Context, dryRun bool) error
+	Close() error
+}
+
+// Store bundles the repositories of one backend
+type Store struct {
+	Users         repository.UserRepository
+	Organizations repository.OrganizationRepository
+	// RateLimits keeps rate limit buckets shared by every replica, nil when the
+	// backend cannot
+	RateLimits ratelimit.Store
+	// Driver is the configured database.driver
+	Driver  string
+	backend backend
+}
+
+// New connects to the backend described by cfg, retrying with backoff for up to
+// database.connect_timeout, and applies pending migrations unless they are disabled
+func New(logger *slog.Logger, cfg config.DatabaseConfig) (*Store, error) {
+	s, err := ConnectWithRetry(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SkipMigrations {
+		return s, nil
+	}
+	if err := s.Migrate(false); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ConnectWithRetry calls Connect until it succeeds or database.connect_timeout has
+// passed, so the API can start before the database is up
+func ConnectWithRetry(logger *slog.Logger, cfg config.DatabaseConfig) (*Store, error) {
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		s, err := Connect(logger, cfg)
+		if err == nil {
+			return s, nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("giving up connecting to the database after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("database connection failed, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// Connect connects to the backend described by cfg without migrating it
+func Connect(logger *slog.Logger, cfg config.DatabaseConfig) (*Store, error) {
+	s, err := connect(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.Driver = cfg.Driver
+	s.Users = repository.WithUserTimeouts(s.Users, cfg.Timeouts)
+	s.Organizations = repository.WithOrganizationTimeouts(s.Organizations, cfg.Timeouts)
+	return s, nil
+}
+
+func connect(logger *slog.Logger, cfg config.DatabaseConfig) (*Store, error) {
+	// Bound a single attempt, the MongoDB driver otherwise waits 30s for server selection
+	ctx := context.Background()
+	if d := cfg.Timeouts.For("Connect"); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	switch cfg.Driver {
+	case "", DriverMongoDB:
+		db, err := database.Connect(ctx, cfg.URI.Value(), cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("using MongoDB database", "name", cfg.Name)
+		return &Store{
+			Users:         mongorepo.NewUserRepository(db.DB),
+			Organizations: mongorepo.NewOrganizationRepository(db.DB),
+			RateLimits:    mongorepo.NewRateLimitStore(db.DB),
+			backend:       &mongoBackend{db: db, migrator: database.NewMigrator(logger, db.DB)},
+		}, nil
+
+	case DriverPostgres:
+		db, err := postgres.Open(ctx, cfg.URI.Value())
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("using PostgreSQL database")
+		return &Store{
+			Users:         postgres.NewUserRepository(db),
+			Organizations: postgres.NewOrganizationRepository(db),
+			backend:       &postgresBackend{db: db, logger: logger},
+		}, nil
+
+	case DriverMemory:
+		logger.Warn("using in-memory database, data is lost on restart")
+		return &Store{
+			Users:         memory.NewUserRepository(),
+			Organizations: memory.NewOrganizationRepository(),
+			backend:       memoryBackend{},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+}
+
+// Ping checks that the database is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	return s.backend.Ping(ctx)
+}
+
+// PendingMigrations returns the number of schema migrations not applied yet
+func (s *Store) PendingMigrations(ctx context.Context) (int, error) {
+	return s.backend.Pending(ctx)
+}
+
+// Migrate applies the backend's pending schema migrations. With dryRun set it
+// only logs what would be applied.
+func (s *Store) Migrate(dryRun bool) error {
+	return s.backend.Migrate(context.Background(), dryRun)
+}
+
+// Close releases the backend connection
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+type mongoBackend struct {
+	db       *database.DB
+	migrator *database.Migrator
+}
+
+func (b *mongoBackend) Ping(ctx context.Context) error {
+	return b.db.Ping(ctx)
+}
+
+func (b *mongoBackend) Pending(ctx context.Context) (int, error) {
+	pending, err := b.migrator.Pending(ctx)
+	return len(pending), err
+}
+
+func (b *mongoBackend) Migrate(ctx context.Context, dryRun bool) error {
+	return b.migrator.Migrate(ctx, dryRun)
+}
+
+func (b *mongoBackend) Close() error {
+	return b.db.Close()
+}
+
+type postgresBackend struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func (b *postgresBackend) Ping(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+func (b *postgresBackend) Pending(ctx context.Context) (int, error) {
+	pending, err := postgres.Pending(ctx, b.db)
+	return len(pending), err
+}
+
+func (b *postgresBackend) Migrate(ctx context.Context, dryRun bool) error {
+	pending, err := postgres.Pending(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		b.logger.Info("PostgreSQL schema is up to date")
+		return nil
+	}
+	for _, migration := range pending {
+		if dryRun {
+			b.logger.Info("would apply migration", "version", migration.Version, "name", migration.Name)
+		} else {
+			b.logger.Info("applying migration", "version", migration.Version, "name", migration.Name)
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	return postgres.Migrate(ctx, b.db)
+}
+
+func (b *postgresBackend) Close() error {
+	return b.db.Close()
+}
+
+type memoryBackend struct{}
+
+func (memoryBackend) Ping(context.Context) error           { return nil }
+func (memoryBackend) Pending(context.Context) (int, error) { return 0, nil }
+func (memoryBackend) Migrate(context.Context, bool) error  { return nil }
+func (memoryBackend) Close() error                         { return nil }
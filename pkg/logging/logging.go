@@ -0,0 +1,186 @@
+// Package logging builds the structured loggers used throughout the application.
+// Every package logs through a named child of the root logger so its level can be
+// tuned separately, request scoped attributes travel in the context, and
+// passwords and tokens are redacted before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"Go-api/pkg/config"
+)
+
+// PackageKey is the attribute naming the package a record was logged from
+const PackageKey = "logger"
+
+// Levels holds the minimum level of the root logger and of each package, and can
+// be changed while the application runs
+type Levels struct {
+	current atomic.Pointer[levels]
+}
+
+type levels struct {
+	root     slog.Level
+	packages map[string]slog.Level
+}
+
+// Set applies the level and per-package levels of cfg
+func (l *Levels) Set(cfg config.LoggingConfig) error {
+	root, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	next := &levels{root: root, packages: make(map[string]slog.Level, len(cfg.Packages))}
+	for name, value := range cfg.Packages {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+		next.packages[name] = level
+	}
+	l.current.Store(next)
+	return nil
+}
+
+// Enabled reports whether a record at level from the named package is logged
+func (l *Levels) Enabled(pkg string, level slog.Level) bool {
+	current := l.current.Load()
+	if min, ok := current.packages[pkg]; ok {
+		return level >= min
+	}
+	return level >= current.root
+}
+
+// ParseLevel parses one of debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// New returns the root logger writing to w in the configured format, along with
+// the levels to update when the configuration is reloaded
+func New(w io.Writer, cfg config.LoggingConfig) (*slog.Logger, *Levels, error) {
+	levels := &Levels{}
+	if err := levels.Set(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// Filtering happens in handler.Enabled, the base handler lets everything through
+	options := &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: redactAttr,
+	}
+	var base slog.Handler
+	if strings.EqualFold(cfg.Format, "text") {
+		base = slog.NewTextHandler(w, options)
+	} else {
+		base = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(&handler{next: base, levels: levels}), levels, nil
+}
+
+// For returns the logger of the named package, whose level can be set with
+// logging.packages.<name>
+func For(logger *slog.Logger, pkg string) *slog.Logger {
+	h, ok := logger.Handler().(*handler)
+	if !ok {
+		return logger.With(PackageKey, pkg)
+	}
+	return slog.New(&handler{
+		next:   h.next.WithAttrs([]slog.Attr{slog.String(PackageKey, pkg)}),
+		levels: h.levels,
+		pkg:    pkg,
+	})
+}
+
+// AuditPackage names the logger of the audit trail
+const AuditPackage = "audit"
+
+// Audit returns the logger of the audit trail. Unlike the package loggers it
+// ignores logging.level and logging.packages: its records are always written,
+// so the trail never depends on the log level.
+func Audit(logger *slog.Logger) *slog.Logger {
+	h, ok := logger.Handler().(*handler)
+	if !ok {
+		return logger.With(PackageKey, AuditPackage)
+	}
+	return slog.New(&handler{
+		next:   h.next.WithAttrs([]slog.Attr{slog.String(PackageKey, AuditPackage)}),
+		levels: h.levels,
+		pkg:    AuditPackage,
+		always: true,
+	})
+}
+
+// Discard returns a logger that drops every record
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// handler filters records by package level and adds the request attributes and
+// trace IDs stored in the context
+type handler struct {
+	next   slog.Handler
+	levels *Levels
+	pkg    string
+	// always writes every record whatever the levels, for the audit trail
+	always bool
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.always || h.levels.Enabled(h.pkg, level)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := contextAttrs(ctx)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)],
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	if len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs), levels: h.levels, pkg: h.pkg, always: h.always}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), levels: h.levels, pkg: h.pkg, always: h.always}
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose log records carry attrs in addition to the
+// attributes already stored in ctx. The middleware uses it to tag everything
+// logged while serving a request with the request ID, user and organization.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := contextAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"Go-api/pkg/config"
+)
+
+func TestAuditIgnoresLevels(t *testing.T) {
+	var buf bytes.Buffer
+	root, levels, err := New(&buf, config.LoggingConfig{Level: "warn", Format: "json", Packages: map[string]string{AuditPackage: "error"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	audit := Audit(root)
+	For(root, "store").Info("dropped")
+	root.Info("dropped")
+	audit.Info("organization restored", "admin_id", "1")
+	audit.With("request_id", "abc").Debug("user suspended")
+	// Reloaded levels do not change that either
+	if err := levels.Set(config.LoggingConfig{Level: "error"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	audit.Info("user reinstated")
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record is not JSON: %v: %s", err, line)
+		}
+		if record[PackageKey] != AuditPackage {
+			t.Errorf("record %s is not from the audit logger", line)
+		}
+		got = append(got, record["msg"].(string))
+	}
+	want := []string{"organization restored", "user suspended", "user reinstated"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
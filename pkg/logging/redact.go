@@ -0,0 +1,225 @@
+package logging
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as substrings of lower-cased attribute and map keys
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// IsSensitive reports whether a value logged under key must be redacted
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr is the ReplaceAttr hook of the base handler
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		if redacted, ok := redactValue(attr.Value.Any()); ok {
+			return slog.Any(attr.Key, redacted)
+		}
+	}
+	return attr
+}
+
+// redactValue copies logged payloads such as decoded request bodies, gin.H or
+// bound DTOs, replacing the values of sensitive keys at any depth. Structs are
+// copied into maps keyed by their JSON names when they may hold a sensitive
+// value, see mayHoldSecrets. ok is false for values that are kept as they are.
+func redactValue(value any) (any, bool) {
+	return redactAt(reflect.ValueOf(value), 0)
+}
+
+// maxDepth bounds redactAt on cyclic values, deeper values are redacted whole
+const maxDepth = 32
+
+func redactAt(v reflect.Value, depth int) (any, bool) {
+	if depth > maxDepth {
+		return Redacted, true
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return redactAt(v.Elem(), depth)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if IsSensitive(key) {
+				out[key] = Redacted
+			} else {
+				out[key] = redactOrKeep(iter.Value(), depth+1)
+			}
+		}
+		return out, true
+	case reflect.Slice:
+		if !mayHoldSecrets(v.Type().Elem()) {
+			return nil, false
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactOrKeep(v.Index(i), depth+1)
+		}
+		return out, true
+	case reflect.Pointer:
+		if v.IsNil() || formatsItself(v) || !mayHoldSecrets(v.Type()) {
+			return nil, false
+		}
+		return redactAt(v.Elem(), depth+1)
+	case reflect.Struct:
+		if formatsItself(v) || !mayHoldSecrets(v.Type()) {
+			return nil, false
+		}
+		out := map[string]any{}
+		redactFields(v, out, depth)
+		return out, true
+	}
+	return nil, false
+}
+
+// formatsItself reports whether v decides how it is logged, as errors do, in
+// which case it is not copied field by field
+func formatsItself(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case error, fmt.Stringer, json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// redactFields copies the exported fields of the struct v into out under their
+// JSON names, as encoding/json would marshal them
+func redactFields(v reflect.Value, out map[string]any, depth int) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		// Untagged embedded structs are flattened, even unexported ones
+		if field.Anonymous && name == field.Name && value.Kind() == reflect.Struct {
+			redactFields(value, out, depth)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if IsSensitive(name) {
+			out[name] = Redacted
+		} else {
+			out[name] = redactOrKeep(value, depth+1)
+		}
+	}
+}
+
+// jsonName returns the name encoding/json gives the field, ok is false when it
+// is skipped
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// secretTypes caches mayHoldSecrets by type
+var secretTypes sync.Map
+
+// mayHoldSecrets reports whether values of t may hold a sensitive value: maps
+// and interfaces can hold anything, structs when a field is sensitive or may
+// hold one. Other structs, such as time.Time, are logged as they are.
+func mayHoldSecrets(t reflect.Type) bool {
+	if cached, ok := secretTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := holdsSecrets(t, map[reflect.Type]bool{})
+	secretTypes.Store(t, result)
+	return result
+}
+
+// holdsSecrets is mayHoldSecrets without the cache. seen holds the types being
+// inspected, which recursive types reach again.
+func holdsSecrets(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return holdsSecrets(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonName(field)
+			if !ok || !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if IsSensitive(name) || holdsSecrets(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactOrKeep returns the redacted copy of v, or v itself
+func redactOrKeep(v reflect.Value, depth int) any {
+	if redacted, ok := redactAt(v, depth); ok {
+		return redacted
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	// Promoted from an unexported embedded struct
+	return plain(v)
+}
+
+// plain returns the value of a field that cannot be read with Interface
+func plain(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
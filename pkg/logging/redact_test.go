@@ -0,0 +1,107 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/config"
+	"Go-api/pkg/logging"
+)
+
+// logged writes one record with the attribute value under key and returns it
+// decoded
+func logged(t *testing.T, key string, value any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, config.LoggingConfig{Level: "info", Format: "json"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.Info("test", key, value)
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("secret written to the log: %s", buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("record is not JSON: %v: %s", err, buf.String())
+	}
+	return record
+}
+
+func TestRedaction(t *testing.T) {
+	type credentials struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	type embedded struct {
+		credentials
+		Tags []string `json:"tags"`
+	}
+	type tokens struct {
+		AccessToken string `json:"access_token"`
+		Note        any    `json:"note"`
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value any
+	}{
+		{"Key", "password", "hunter2"},
+		{"Map", "body", gin.H{"email": "ada@example.com", "password": "hunter2"}},
+		{"NestedMap", "body", map[string]any{"user": map[string]string{"refresh_token": "hunter2"}}},
+		{"Slice", "body", []any{gin.H{"secret": "hunter2"}}},
+		{"Struct", "body", credentials{Email: "ada@example.com", Password: "hunter2"}},
+		{"StructPointer", "body", &credentials{Email: "ada@example.com", Password: "hunter2"}},
+		{"StructSlice", "body", []credentials{{Email: "ada@example.com", Password: "hunter2"}}},
+		{"Embedded", "body", embedded{credentials: credentials{Password: "hunter2"}}},
+		{"Interface", "body", tokens{AccessToken: "hunter2", Note: gin.H{"cookie": "hunter2"}}},
+		{"DTO", "body", &dto.SignUpRequest{Name: "Ada", Email: "ada@example.com", Password: "hunter2"}},
+		{"RefreshDTO", "body", dto.RefreshRequest{RefreshToken: "hunter2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged(t, tt.key, tt.value)
+		})
+	}
+}
+
+func TestRedactionKeepsNames(t *testing.T) {
+	record := logged(t, "body", &dto.SignUpRequest{Name: "Ada", Email: "ada@example.com", Password: "hunter2"})
+	body, ok := record["body"].(map[string]any)
+	if !ok {
+		t.Fatalf("body = %#v, want an object", record["body"])
+	}
+	want := map[string]any{"name": "Ada", "email": "ada@example.com", "password": logging.Redacted}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("body[%q] = %#v, want %#v", key, body[key], value)
+		}
+	}
+}
+
+func TestRedactionKeepsOtherValues(t *testing.T) {
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	record := logged(t, "at", at)
+	if record["at"] != at.Format(time.RFC3339) {
+		t.Errorf("time logged as %#v", record["at"])
+	}
+
+	record = logged(t, "error", errors.New("connection refused"))
+	if record["error"] != "connection refused" {
+		t.Errorf("error logged as %#v", record["error"])
+	}
+
+	type point struct{ X, Y int }
+	record = logged(t, "point", point{1, 2})
+	if got, ok := record["point"].(map[string]any); !ok || got["X"] != 1.0 || got["Y"] != 2.0 {
+		t.Errorf("struct logged as %#v", record["point"])
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"Go-api/pkg/database/repository"
+)
+
+// countTimeout bounds the queries run on every scrape
+const countTimeout = 2 * time.Second
+
+var (
+	usersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "users"),
+		"Number of registered users.", nil, nil,
+	)
+	organizationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "organizations"),
+		"Number of organizations.", nil, nil,
+	)
+)
+
+// businessCollector counts users and organizations when scraped, so the gauges
+// are exact across instances sharing a database
+type businessCollector struct {
+	users         repository.UserRepository
+	organizations repository.OrganizationRepository
+}
+
+// RegisterBusinessGauges exports the user and organization counts
+func (m *Metrics) RegisterBusinessGauges(users repository.UserRepository, organizations repository.OrganizationRepository) {
+	m.registry.MustRegister(&businessCollector{users: users, organizations: organizations})
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- usersDesc
+	ch <- organizationsDesc
+}
+
+// Collect skips a gauge whose count fails, the scrape of every other metric
+// still succeeds
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	if count, err := c.users.CountUsers(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(usersDesc, prometheus.GaugeValue, float64(count))
+	}
+	if count, err := c.organizations.CountOrganizations(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(organizationsDesc, prometheus.GaugeValue, float64(count))
+	}
+}
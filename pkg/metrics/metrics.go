@@ -0,0 +1,166 @@
+// Package metrics collects the Prometheus metrics of the API: HTTP traffic,
+// repository operations, authentication outcomes and business gauges.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "goapi"
+
+// Authentication events, the event label of goapi_auth_events_total
+const (
+	AuthSignIn      = "sign_in"
+	AuthRefresh     = "refresh"
+	AuthAccessToken = "access_token"
+)
+
+// Authentication outcomes, the outcome label of goapi_auth_events_total
+const (
+	OutcomeSuccess            = "success"
+	OutcomeInvalidCredentials = "invalid_credentials"
+	OutcomeInvalidToken       = "invalid_token"
+	OutcomeExpiredToken       = "expired_token"
+	OutcomeMissingToken       = "missing_token"
+	OutcomeInactiveAccount    = "inactive_account"
+	OutcomeError              = "error"
+)
+
+// Metrics owns a registry with the application's collectors. A nil *Metrics is
+// valid and records nothing, so components can be built without metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight prometheus.Gauge
+	deprecatedCalls  *prometheus.CounterVec
+	rateLimited      *prometheus.CounterVec
+
+	repositoryDuration *prometheus.HistogramVec
+	repositoryErrors   *prometheus.CounterVec
+
+	authEvents *prometheus.CounterVec
+}
+
+// New registers the application metrics along with the Go runtime and process
+// collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		deprecatedCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "deprecated_requests_total",
+			Help:      "Requests to deprecated routes by method and route template, to tell when they can be removed.",
+		}, []string{"method", "route"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "rate_limited_requests_total",
+			Help:      "Requests refused with 429 by route group.",
+		}, []string{"group"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of repository operations.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"repository", "operation"}),
+		repositoryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_errors_total",
+			Help:      "Failed repository operations by kind: timeout, canceled, rejected (a domain error such as a duplicate) or error.",
+		}, []string{"repository", "operation", "kind"}),
+		authEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "events_total",
+			Help:      "Sign-ins, token refreshes and access token checks by outcome.",
+		}, []string{"event", "outcome"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.requestsInFlight,
+		m.deprecatedCalls,
+		m.rateLimited,
+		m.repositoryDuration,
+		m.repositoryErrors,
+		m.authEvents,
+	)
+	return m
+}
+
+// Registry returns the registry for registering additional collectors
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the registry in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RequestStarted tracks a request in flight, call the returned function when it is done
+func (m *Metrics) RequestStarted() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.requestsInFlight.Inc()
+	return m.requestsInFlight.Dec
+}
+
+// ObserveRequest records a served request. route is the template the request
+// matched, e.g. /organization/:organization_id, so IDs do not explode the label space.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// DeprecatedRequest counts a request to a deprecated route
+func (m *Metrics) DeprecatedRequest(method, route string) {
+	if m == nil {
+		return
+	}
+	m.deprecatedCalls.WithLabelValues(method, route).Inc()
+}
+
+// RateLimited counts a request refused by the rate limit of a route group
+func (m *Metrics) RateLimited(group string) {
+	if m == nil {
+		return
+	}
+	m.rateLimited.WithLabelValues(group).Inc()
+}
+
+// Auth records the outcome of an authentication event
+func (m *Metrics) Auth(event, outcome string) {
+	if m == nil {
+		return
+	}
+	m.authEvents.WithLabelValues(event, outcome).Inc()
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+)
+
+// observe times one repository operation and counts its failure
+func (m *Metrics) observe(repo, operation string, start time.Time, err error) {
+	m.repositoryDuration.WithLabelValues(repo, operation).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+
+	kind := "error"
+	switch {
+	case errors.Is(err, repository.ErrTimeout):
+		kind = "timeout"
+	case errors.Is(err, repository.ErrCanceled):
+		kind = "canceled"
+	case repository.IsDomainError(err):
+		kind = "rejected"
+	}
+	m.repositoryErrors.WithLabelValues(repo, operation, kind).Inc()
+}
+
+type userRepository struct {
+	next    repository.UserRepository
+	metrics *Metrics
+}
+
+// InstrumentUsers wraps a user repository to record the latency and errors of
+// every operation
+func (m *Metrics) InstrumentUsers(next repository.UserRepository) repository.UserRepository {
+	if m == nil {
+		return next
+	}
+	return &userRepository{next: next, metrics: m}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *models.User) (err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "CreateUser", start, err) }(time.Now())
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, id string, user *models.User) (err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "UpdateUser", start, err) }(time.Now())
+	return r.next.UpdateUser(ctx, id, user)
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "DeleteUser", start, err) }(time.Now())
+	return r.next.DeleteUser(ctx, id)
+}
+
+func (r *userRepository) GetUser(ctx context.Context, id string) (_ *models.User, err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "GetUser", start, err) }(time.Now())
+	return r.next.GetUser(ctx, id)
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (_ *models.User, err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "GetUserByEmail", start, err) }(time.Now())
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r *userRepository) AuthenticateUser(ctx context.Context, email, password string) (_ *models.User, err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "AuthenticateUser", start, err) }(time.Now())
+	return r.next.AuthenticateUser(ctx, email, password)
+}
+
+func (r *userRepository) CountUsers(ctx context.Context) (_ int64, err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "CountUsers", start, err) }(time.Now())
+	return r.next.CountUsers(ctx)
+}
+
+func (r *userRepository) GetAllUsers(ctx context.Context) (_ []models.User, err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "GetAllUsers", start, err) }(time.Now())
+	return r.next.GetAllUsers(ctx)
+}
+
+func (r *userRepository) SetState(ctx context.Context, id string, state string) (err error) {
+	defer func(start time.Time) { r.metrics.observe("user", "SetState", start, err) }(time.Now())
+	return r.next.SetState(ctx, id, state)
+}
+
+type organizationRepository struct {
+	next    repository.OrganizationRepository
+	metrics *Metrics
+}
+
+// InstrumentOrganizations wraps an organization repository to record the latency
+// and errors of every operation
+func (m *Metrics) InstrumentOrganizations(next repository.OrganizationRepository) repository.OrganizationRepository {
+	if m == nil {
+		return next
+	}
+	return &organizationRepository{next: next, metrics: m}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (_ string, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "CreateOrganization", start, err) }(time.Now())
+	return r.next.CreateOrganization(ctx, org)
+}
+
+func (r *organizationRepository) GetOrganizationByName(ctx context.Context, name string) (_ *models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetOrganizationByName", start, err) }(time.Now())
+	return r.next.GetOrganizationByName(ctx, name)
+}
+
+func (r *organizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (_ *models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "UpdateOrganization", start, err) }(time.Now())
+	return r.next.UpdateOrganization(ctx, id, org, version)
+}
+
+func (r *organizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) (err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "DeleteOrganization", start, err) }(time.Now())
+	return r.next.DeleteOrganization(ctx, id, version)
+}
+
+func (r *organizationRepository) GetAllOrganizations(ctx context.Context) (_ []models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetAllOrganizations", start, err) }(time.Now())
+	return r.next.GetAllOrganizations(ctx)
+}
+
+func (r *organizationRepository) GetOrganizationsByMember(ctx context.Context, email string) (_ []models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetOrganizationsByMember", start, err) }(time.Now())
+	return r.next.GetOrganizationsByMember(ctx, email)
+}
+
+func (r *organizationRepository) GetOrganizationByID(ctx context.Context, id string) (_ *models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetOrganizationByID", start, err) }(time.Now())
+	return r.next.GetOrganizationByID(ctx, id)
+}
+
+func (r *organizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (_ int, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetAccessLevelByEmail", start, err) }(time.Now())
+	return r.next.GetAccessLevelByEmail(ctx, organizationID, email)
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) (err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "AddMember", start, err) }(time.Now())
+	return r.next.AddMember(ctx, organizationID, member)
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, organizationID, email string) (err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "RemoveMember", start, err) }(time.Now())
+	return r.next.RemoveMember(ctx, organizationID, email)
+}
+
+func (r *organizationRepository) CountOrganizations(ctx context.Context) (_ int64, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "CountOrganizations", start, err) }(time.Now())
+	return r.next.CountOrganizations(ctx)
+}
+
+func (r *organizationRepository) ForceDeleteOrganization(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "ForceDeleteOrganization", start, err) }(time.Now())
+	return r.next.ForceDeleteOrganization(ctx, id)
+}
+
+func (r *organizationRepository) RestoreOrganization(ctx context.Context, id string) (_ *models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "RestoreOrganization", start, err) }(time.Now())
+	return r.next.RestoreOrganization(ctx, id)
+}
+
+func (r *organizationRepository) GetDeletedOrganizations(ctx context.Context) (_ []models.Organization, err error) {
+	defer func(start time.Time) { r.metrics.observe("organization", "GetDeletedOrganizations", start, err) }(time.Now())
+	return r.next.GetDeletedOrganizations(ctx)
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreForgetsFullBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	take := func(key string, limit Limit) {
+		t.Helper()
+		if _, err := store.Take(context.Background(), key, limit); err != nil {
+			t.Fatalf("Take(%s): %v", key, err)
+		}
+	}
+
+	take("full", oneASecond)
+	now = now.Add(2 * time.Second)
+	// Refills a token a minute, it is not full again before the next sweep
+	take("slow", Limit{PerMinute: 1, Burst: 3})
+	now = now.Add(sweepInterval - time.Second)
+	take("new", oneASecond)
+
+	if _, ok := store.buckets["full"]; ok {
+		t.Error("the bucket full again was kept")
+	}
+	if _, ok := store.buckets["slow"]; !ok {
+		t.Error("the bucket still refilling was dropped")
+	}
+	if len(store.buckets) != 2 {
+		t.Errorf("store has %d buckets, want slow and new", len(store.buckets))
+	}
+}
@@ -0,0 +1,71 @@
+// Package ratelimit implements token bucket rate limiting. Each key owns a
+// bucket of Burst tokens refilled at PerMinute tokens a minute; a request takes
+// one token and is refused when the bucket is empty. The buckets are kept in a
+// Store: in process with NewMemoryStore, or shared by every replica in MongoDB.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the policy of a bucket
+type Limit struct {
+	// PerMinute is the sustained rate
+	PerMinute int
+	// Burst is the capacity of the bucket, how many requests may be made at once
+	Burst int
+}
+
+// perSecond is the refill rate
+func (l Limit) perSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is how long until a token is available, zero when Allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the buckets
+type Store interface {
+	// Take takes a token from the bucket of key, which starts full
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Refill returns the tokens of a bucket that held tokens elapsed ago
+func Refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * limit.perSecond()
+	}
+	return math.Min(tokens, float64(limit.Burst))
+}
+
+// NewResult describes a bucket left with tokens after a take that was allowed
+// or not
+func NewResult(allowed bool, tokens float64, limit Limit) Result {
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     wait(float64(limit.Burst)-tokens, limit),
+	}
+	if !allowed {
+		result.RetryAfter = wait(1-tokens, limit)
+	}
+	return result
+}
+
+// wait returns how long the bucket takes to gain missing tokens
+func wait(missing float64, limit Limit) time.Duration {
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / limit.perSecond() * float64(time.Second))
+}
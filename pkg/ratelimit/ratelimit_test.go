@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// oneASecond refills a token a second
+var oneASecond = Limit{PerMinute: 60, Burst: 3}
+
+func TestRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{"nothing elapsed", 1, 0, 1},
+		{"one second", 0, time.Second, 1},
+		{"fractions", 0.5, 500 * time.Millisecond, 1},
+		{"capped at the burst", 1, 10 * time.Second, 3},
+		{"clock went back", 2, -time.Second, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Refill(tt.tokens, tt.elapsed, oneASecond); got != tt.want {
+				t.Errorf("Refill(%v, %v) = %v, want %v", tt.tokens, tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed bool
+		tokens  float64
+		want    Result
+	}{
+		{"full", true, 3, Result{Allowed: true, Remaining: 3}},
+		{"allowed", true, 2, Result{Allowed: true, Remaining: 2, Reset: time.Second}},
+		{"last token", true, 0, Result{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+		{"partial token", true, 1.5, Result{Allowed: true, Remaining: 1, Reset: 1500 * time.Millisecond}},
+		{"refused", false, 0, Result{Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second}},
+		{"refused refilling", false, 0.25, Result{Remaining: 0, RetryAfter: 750 * time.Millisecond, Reset: 2750 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewResult(tt.allowed, tt.tokens, oneASecond); got != tt.want {
+				t.Errorf("NewResult(%v, %v) = %+v, want %+v", tt.allowed, tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Handler runs a controller method inside its own span named after it, e.g.
+// OrganizationController.GetOrgByID. The span is a child of the request span,
+// and repository calls made by the controller become its children.
+func Handler(name string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		spanCtx, span := tracer().Start(ctx.Request.Context(), name)
+		defer span.End()
+
+		request := ctx.Request
+		ctx.Request = request.WithContext(spanCtx)
+		handler(ctx)
+		// Middleware finishing after the handler must not see the ended span as current
+		ctx.Request = request
+
+		if status := ctx.Writer.Status(); status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+)
+
+// start opens a span for one repository operation
+func start(ctx context.Context, repo, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, repo+"."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(append(attrs, attribute.String("repository.operation", operation))...),
+	)
+}
+
+// end records a failed operation on the span and ends it. Domain errors such as a
+// duplicate email are answers, not failures, and only become an event.
+func end(span trace.Span, err error) {
+	if err != nil {
+		if repository.IsDomainError(err) {
+			span.AddEvent("rejected", trace.WithAttributes(attribute.String("error", err.Error())))
+		} else {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+type userRepository struct {
+	next repository.UserRepository
+}
+
+// TraceUsers wraps a user repository to run every operation in a span
+func TraceUsers(next repository.UserRepository) repository.UserRepository {
+	return &userRepository{next: next}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *models.User) (err error) {
+	ctx, span := start(ctx, "UserRepository", "CreateUser")
+	defer func() { end(span, err) }()
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, id string, user *models.User) (err error) {
+	ctx, span := start(ctx, "UserRepository", "UpdateUser", attribute.String("user.id", id))
+	defer func() { end(span, err) }()
+	return r.next.UpdateUser(ctx, id, user)
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id string) (err error) {
+	ctx, span := start(ctx, "UserRepository", "DeleteUser", attribute.String("user.id", id))
+	defer func() { end(span, err) }()
+	return r.next.DeleteUser(ctx, id)
+}
+
+func (r *userRepository) GetUser(ctx context.Context, id string) (_ *models.User, err error) {
+	ctx, span := start(ctx, "UserRepository", "GetUser", attribute.String("user.id", id))
+	defer func() { end(span, err) }()
+	return r.next.GetUser(ctx, id)
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (_ *models.User, err error) {
+	ctx, span := start(ctx, "UserRepository", "GetUserByEmail")
+	defer func() { end(span, err) }()
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r *userRepository) AuthenticateUser(ctx context.Context, email, password string) (_ *models.User, err error) {
+	ctx, span := start(ctx, "UserRepository", "AuthenticateUser")
+	defer func() { end(span, err) }()
+	return r.next.AuthenticateUser(ctx, email, password)
+}
+
+func (r *userRepository) CountUsers(ctx context.Context) (_ int64, err error) {
+	ctx, span := start(ctx, "UserRepository", "CountUsers")
+	defer func() { end(span, err) }()
+	return r.next.CountUsers(ctx)
+}
+
+func (r *userRepository) GetAllUsers(ctx context.Context) (_ []models.User, err error) {
+	ctx, span := start(ctx, "UserRepository", "GetAllUsers")
+	defer func() { end(span, err) }()
+	return r.next.GetAllUsers(ctx)
+}
+
+func (r *userRepository) SetState(ctx context.Context, id string, state string) (err error) {
+	ctx, span := start(ctx, "UserRepository", "SetState",
+		attribute.String("user.id", id), attribute.String("user.state", state))
+	defer func() { end(span, err) }()
+	return r.next.SetState(ctx, id, state)
+}
+
+type organizationRepository struct {
+	next repository.OrganizationRepository
+}
+
+// TraceOrganizations wraps an organization repository to run every operation in a span
+func TraceOrganizations(next repository.OrganizationRepository) repository.OrganizationRepository {
+	return &organizationRepository{next: next}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (_ string, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "CreateOrganization")
+	defer func() { end(span, err) }()
+	return r.next.CreateOrganization(ctx, org)
+}
+
+func (r *organizationRepository) GetOrganizationByName(ctx context.Context, name string) (_ *models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetOrganizationByName")
+	defer func() { end(span, err) }()
+	return r.next.GetOrganizationByName(ctx, name)
+}
+
+func (r *organizationRepository) UpdateOrganization(ctx context.Context, id string, org *models.Organization, version int64) (_ *models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "UpdateOrganization",
+		attribute.String("organization.id", id), attribute.Int64("organization.version", version))
+	defer func() { end(span, err) }()
+	return r.next.UpdateOrganization(ctx, id, org, version)
+}
+
+func (r *organizationRepository) DeleteOrganization(ctx context.Context, id string, version int64) (err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "DeleteOrganization",
+		attribute.String("organization.id", id), attribute.Int64("organization.version", version))
+	defer func() { end(span, err) }()
+	return r.next.DeleteOrganization(ctx, id, version)
+}
+
+func (r *organizationRepository) GetAllOrganizations(ctx context.Context) (_ []models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetAllOrganizations")
+	defer func() { end(span, err) }()
+	return r.next.GetAllOrganizations(ctx)
+}
+
+func (r *organizationRepository) GetOrganizationsByMember(ctx context.Context, email string) (_ []models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetOrganizationsByMember")
+	defer func() { end(span, err) }()
+	return r.next.GetOrganizationsByMember(ctx, email)
+}
+
+func (r *organizationRepository) GetOrganizationByID(ctx context.Context, id string) (_ *models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetOrganizationByID", attribute.String("organization.id", id))
+	defer func() { end(span, err) }()
+	return r.next.GetOrganizationByID(ctx, id)
+}
+
+func (r *organizationRepository) GetAccessLevelByEmail(ctx context.Context, organizationID, email string) (_ int, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetAccessLevelByEmail", attribute.String("organization.id", organizationID))
+	defer func() { end(span, err) }()
+	return r.next.GetAccessLevelByEmail(ctx, organizationID, email)
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, organizationID string, member *models.OrganizationMember) (err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "AddMember", attribute.String("organization.id", organizationID))
+	defer func() { end(span, err) }()
+	return r.next.AddMember(ctx, organizationID, member)
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, organizationID, email string) (err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "RemoveMember", attribute.String("organization.id", organizationID))
+	defer func() { end(span, err) }()
+	return r.next.RemoveMember(ctx, organizationID, email)
+}
+
+func (r *organizationRepository) CountOrganizations(ctx context.Context) (_ int64, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "CountOrganizations")
+	defer func() { end(span, err) }()
+	return r.next.CountOrganizations(ctx)
+}
+
+func (r *organizationRepository) ForceDeleteOrganization(ctx context.Context, id string) (err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "ForceDeleteOrganization", attribute.String("organization.id", id))
+	defer func() { end(span, err) }()
+	return r.next.ForceDeleteOrganization(ctx, id)
+}
+
+func (r *organizationRepository) RestoreOrganization(ctx context.Context, id string) (_ *models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "RestoreOrganization", attribute.String("organization.id", id))
+	defer func() { end(span, err) }()
+	return r.next.RestoreOrganization(ctx, id)
+}
+
+func (r *organizationRepository) GetDeletedOrganizations(ctx context.Context) (_ []models.Organization, err error) {
+	ctx, span := start(ctx, "OrganizationRepository", "GetDeletedOrganizations")
+	defer func() { end(span, err) }()
+	return r.next.GetDeletedOrganizations(ctx)
+}
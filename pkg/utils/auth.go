@@ -1,68 +1,58 @@
 package utils
 
 import (
-	"net/http"
-	"strings"
-	"time"
-
-	"github.com/gin-gonic/gin"
+	"errors"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Extract the JWT token from the request header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
-			c.Abort()
-			return
-		}
-		tokenString := strings.Split(authHeader, " ")[1]
+// JWTKeys are the keys in effect for issuing and checking tokens
+type JWTKeys struct {
+	// Signing signs new tokens and is always accepted for verification
+	Signing []byte
+	// Verification are retired keys whose tokens are still accepted
+	Verification [][]byte
+}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+// ImpersonatorClaim holds the ID of the platform admin an impersonation token was
+// issued to. Such tokens are access tokens only and cannot be refreshed.
+const ImpersonatorClaim = "impersonator"
+
+// ErrTokenExpired is returned by ParseToken for a correctly signed token whose
+// exp has passed, so clients can be told to refresh rather than sign in again
+var ErrTokenExpired = errors.New("token has expired")
+
+// ParseToken validates an HMAC signed token against the signing key and every
+// verification key, and returns its claims
+func ParseToken(tokenString string, keys JWTKeys) (jwt.MapClaims, error) {
+	err := errors.New("invalid token")
+	expired := false
+	for _, key := range append([][]byte{keys.Signing}, keys.Verification...) {
+		var token *jwt.Token
+		token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			// Check the signing method
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, jwt.ErrSignatureInvalid
 			}
-			// Return the secret key used to sign the token
-			return []byte("your-secret-key"), nil
+			return key, nil
 		})
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization token"})
-			c.Abort()
-			return
+		// The signature checked out, only exp failed
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+			expired = true
 		}
-
-		// Check if the token is valid
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization token"})
-			c.Abort()
-			return
+		if err != nil || !token.Valid {
+			continue
 		}
 
-		// Extract claims from the token
 		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization token"})
-			c.Abort()
-			return
+		if !ok {
+			return nil, errors.New("invalid token claims")
 		}
-
-		// Check expiration
-		exp := time.Unix(int64(claims["exp"].(float64)), 0)
-		if exp.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has expired"})
-			c.Abort()
-			return
-		}
-
-		// Check if the token is blacklisted (if needed)
-
-		// If the token is valid, proceed to the next handler
-		c.Set("user_id", claims["user_id"]) // Set user_id in context for further use
-		c.Next()
+		return claims, nil
+	}
+	if expired {
+		return nil, ErrTokenExpired
 	}
+	return nil, err
 }
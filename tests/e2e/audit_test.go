@@ -0,0 +1,112 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/client"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/logging"
+)
+
+// lockedBuffer is written by the server goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestAuditTrail checks that every admin change is recorded with the log level,
+// and the level of the audit package, turned down
+func TestAuditTrail(t *testing.T) {
+	cfg := testConfig()
+	cfg.Logging = config.LoggingConfig{Level: "warn", Format: "json", Packages: map[string]string{logging.AuditPackage: "error"}}
+	var logs lockedBuffer
+	logger, _, err := logging.New(&logs, cfg.Logging)
+	if err != nil {
+		t.Fatalf("logging.New: %v", err)
+	}
+	users := memory.NewUserRepository()
+	server := httptest.NewServer(routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         users,
+		Organizations: memory.NewOrganizationRepository(),
+		Logger:        logger,
+	}))
+	t.Cleanup(server.Close)
+
+	if err := users.CreateUser(ctx, &models.User{Name: "Root", Email: "root@example.com", Password: "password1", PlatformAdmin: true}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	admin := client.New(server.URL, client.WithHTTPClient(server.Client()))
+	if _, err := admin.SignIn(ctx, "root@example.com", "password1"); err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+	ada := client.New(server.URL, client.WithHTTPClient(server.Client()))
+	signUp(t, ada, "Ada", "ada@example.com")
+	orgID, err := ada.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+	listed, err := admin.AdminListUsers(ctx, "ada@")
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("AdminListUsers = %+v, %v, want Ada", listed, err)
+	}
+
+	steps := []struct {
+		name string
+		call func() error
+	}{
+		{"AdminSuspendUser", func() error { _, err := admin.AdminSuspendUser(ctx, listed[0].ID); return err }},
+		{"AdminReinstateUser", func() error { _, err := admin.AdminReinstateUser(ctx, listed[0].ID); return err }},
+		{"AdminForceDeleteOrganization", func() error { return admin.AdminForceDeleteOrganization(ctx, orgID) }},
+		{"AdminRestoreOrganization", func() error { _, err := admin.AdminRestoreOrganization(ctx, orgID); return err }},
+		{"AdminImpersonate", func() error { _, err := admin.AdminImpersonate(ctx, listed[0].ID, "support ticket 42"); return err }},
+	}
+	for _, step := range steps {
+		if err := step.call(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record struct {
+			Logger  string `json:"logger"`
+			Msg     string `json:"msg"`
+			AdminID string `json:"admin_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %v: %s", err, line)
+		}
+		if record.Logger == logging.AuditPackage && record.AdminID != "" {
+			got = append(got, record.Msg)
+		}
+	}
+	want := []string{"user suspended", "user reinstated", "organization force-deleted", "organization restored", "impersonation started"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("audit trail = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,530 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/problem"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/client"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+	"Go-api/pkg/database/mongodb/models"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/utils"
+)
+
+func TestUsers(t *testing.T) {
+	_, c := newServer(t)
+
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"}); !errors.Is(err, client.ErrNotSignedIn) {
+		t.Errorf("CreateOrganization before sign-in: got %v, want ErrNotSignedIn", err)
+	}
+
+	var stored client.Tokens
+	c = client.New(c.BaseURL(), client.OnTokens(func(tokens client.Tokens) { stored = tokens }))
+	tokens := signUp(t, c, "Ada", "ada@example.com")
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("SignIn returned %+v, want both tokens", tokens)
+	}
+	if stored != tokens {
+		t.Errorf("OnTokens got %+v, want %+v", stored, tokens)
+	}
+
+	_, err := c.SignIn(ctx, "ada@example.com", "wrong-password1")
+	if !errors.Is(err, repository.ErrInvalidPassword) || !errors.Is(err, repository.ErrUnauthenticated) {
+		t.Errorf("SignIn with a wrong password: got %v, want invalid_credentials", err)
+	}
+	if err := c.SignUp(ctx, dto.SignUpRequest{Name: "Ada", Email: "ada@example.com", Password: "password1"}); !errors.Is(err, repository.ErrEmailExists) {
+		t.Errorf("SignUp twice: got %v, want ErrEmailExists", err)
+	}
+}
+
+func TestOrganizations(t *testing.T) {
+	_, c := newServer(t)
+	signUp(t, c, "Ada", "ada@example.com")
+
+	id, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme", Description: "Rockets"})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+	org, err := c.GetOrganization(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrganization: %v", err)
+	}
+	if org.ID != id || org.Name != "Acme" || org.Description != "Rockets" || org.ETag == "" {
+		t.Errorf("GetOrganization returned %+v", org)
+	}
+	if len(org.Members) != 1 || org.Members[0].Email != "ada@example.com" || org.Members[0].AccessLevel != 1 {
+		t.Errorf("creator is not the only administrator: %+v", org.Members)
+	}
+
+	updated, err := c.UpdateOrganization(ctx, id, org.ETag, dto.OrganizationRequest{Name: "Acme Corp", Description: "Rockets"})
+	if err != nil {
+		t.Fatalf("UpdateOrganization: %v", err)
+	}
+	if updated.Name != "Acme Corp" || updated.ETag == org.ETag {
+		t.Errorf("UpdateOrganization returned %+v, want a new name and ETag", updated)
+	}
+	if _, err := c.UpdateOrganization(ctx, id, org.ETag, dto.OrganizationRequest{Name: "Stale"}); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Errorf("UpdateOrganization with a stale ETag: got %v, want ErrVersionMismatch", err)
+	}
+
+	description := "Spaceships"
+	patched, err := c.PatchOrganization(ctx, id, updated.ETag, dto.PatchOrganizationRequest{Description: &description})
+	if err != nil {
+		t.Fatalf("PatchOrganization: %v", err)
+	}
+	if patched.Name != "Acme Corp" || patched.Description != description {
+		t.Errorf("PatchOrganization returned %+v", patched)
+	}
+
+	invitee := client.New(c.BaseURL())
+	signUp(t, invitee, "Grace", "grace@example.com")
+	if err := c.InviteMember(ctx, id, "grace@example.com"); err != nil {
+		t.Fatalf("InviteMember: %v", err)
+	}
+	if err := c.InviteMember(ctx, id, "grace@example.com"); !errors.Is(err, repository.ErrMemberExists) {
+		t.Errorf("InviteMember twice: got %v, want ErrMemberExists", err)
+	}
+	if err := c.InviteMember(ctx, id, "nobody@example.com"); client.Code(err) != client.CodeUserNotFound {
+		t.Errorf("InviteMember of an unknown email: got %v, want %s", err, client.CodeUserNotFound)
+	}
+	// Inviting a member changed the version
+	current, err := c.GetOrganization(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOrganization: %v", err)
+	}
+	if current.ETag == patched.ETag || len(current.Members) != 2 {
+		t.Errorf("GetOrganization after invite returned %+v", current)
+	}
+	orgs, err := invitee.ListOrganizations(ctx)
+	if err != nil || len(orgs) != 1 || orgs[0].ID != id {
+		t.Errorf("ListOrganizations of the invitee = %+v, %v, want only %s", orgs, err, id)
+	}
+	if err := invitee.DeleteOrganization(ctx, id, current.ETag); client.Code(err) != client.CodeInsufficientAccess || !errors.Is(err, repository.ErrForbidden) {
+		t.Errorf("DeleteOrganization by a member: got %v, want %s", err, client.CodeInsufficientAccess)
+	}
+
+	if err := invitee.RemoveMember(ctx, id, "ada@example.com"); client.Code(err) != client.CodeInsufficientAccess {
+		t.Errorf("RemoveMember of the administrator by a member: got %v, want %s", err, client.CodeInsufficientAccess)
+	}
+	if err := c.RemoveMember(ctx, id, "ada@example.com"); client.Code(err) != client.CodeLastAdministrator {
+		t.Errorf("RemoveMember of the last administrator: got %v, want %s", err, client.CodeLastAdministrator)
+	}
+	if err := invitee.RemoveMember(ctx, id, "grace@example.com"); err != nil {
+		t.Fatalf("RemoveMember of oneself: %v", err)
+	}
+	if err := c.RemoveMember(ctx, id, "grace@example.com"); !errors.Is(err, repository.ErrMemberNotFound) {
+		t.Errorf("RemoveMember twice: got %v, want ErrMemberNotFound", err)
+	}
+	if orgs, err := invitee.ListOrganizations(ctx); err != nil || len(orgs) != 0 {
+		t.Errorf("ListOrganizations after leaving = %+v, %v, want none", orgs, err)
+	}
+
+	// Removing a member changed the version again
+	if current, err = c.GetOrganization(ctx, id); err != nil {
+		t.Fatalf("GetOrganization: %v", err)
+	}
+	if err := c.DeleteOrganization(ctx, id, current.ETag); err != nil {
+		t.Fatalf("DeleteOrganization: %v", err)
+	}
+	if _, err := c.GetOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNotFound) || !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetOrganization after delete: got %v, want ErrOrganizationNotFound", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, c := newServer(t)
+	signUp(t, c, "Ada", "ada@example.com")
+
+	err := c.SignUp(ctx, dto.SignUpRequest{Name: " ", Email: "not-an-email", Password: "short"})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("SignUp with invalid fields: got %v, want *client.Error", err)
+	}
+	if apiErr.Status != http.StatusUnprocessableEntity || apiErr.Code != problem.CodeValidationFailed || len(apiErr.Fields) != 3 {
+		t.Errorf("SignUp with invalid fields: got %+v, want 422 validation_failed with 3 fields", apiErr)
+	}
+	if apiErr.RequestID == "" {
+		t.Error("error carries no request ID")
+	}
+
+	if _, err := c.GetOrganization(ctx, "not-an-id"); !errors.Is(err, repository.ErrInvalidID) {
+		t.Errorf("GetOrganization with a malformed ID: got %v, want ErrInvalidID", err)
+	}
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "!"}); client.Code(err) != problem.CodeValidationFailed {
+		t.Errorf("CreateOrganization with an invalid name: got %v, want validation_failed", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	_, c := newServer(t)
+	tokens := signUp(t, c, "Ada", "ada@example.com")
+
+	// Replace the access token with one that expired a minute ago
+	claims, err := utils.ParseToken(tokens.AccessToken, utils.JWTKeys{Signing: []byte(jwtSecret)})
+	if err != nil {
+		t.Fatalf("access token does not parse: %v", err)
+	}
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": claims["user_id"],
+		"exp":     time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	c.SetTokens(client.Tokens{AccessToken: expired, RefreshToken: tokens.RefreshToken})
+
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"}); err != nil {
+		t.Fatalf("CreateOrganization with an expired access token: %v", err)
+	}
+	refreshed := c.Tokens()
+	if refreshed.AccessToken == expired || refreshed.RefreshToken != tokens.RefreshToken {
+		t.Errorf("tokens after refresh: %+v", refreshed)
+	}
+
+	// Without a valid refresh token the session is over
+	c.SetTokens(client.Tokens{AccessToken: expired, RefreshToken: "garbage"})
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Other"}); client.Code(err) != problem.CodeInvalidRefreshToken {
+		t.Errorf("CreateOrganization with an invalid refresh token: got %v, want invalid_refresh_token", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	server, c := newServer(t)
+	signUp(t, c, "Ada", "ada@example.com")
+	id, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+
+	// The first attempt of every request fails before reaching the server
+	transport := &flakyTransport{next: server.Client().Transport}
+	flaky := client.New(server.URL,
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithTokens(c.Tokens()),
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	if _, err := flaky.GetOrganization(ctx, id); err != nil {
+		t.Errorf("GetOrganization was not retried: %v", err)
+	}
+	if got := transport.attempts.Load(); got != 2 {
+		t.Errorf("GetOrganization made %d attempts, want 2", got)
+	}
+
+	// Creating is not idempotent, a retry could create the organization twice
+	transport.attempts.Store(0)
+	if _, err := flaky.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Other"}); err == nil {
+		t.Error("CreateOrganization was retried")
+	}
+	if got := transport.attempts.Load(); got != 1 {
+		t.Errorf("CreateOrganization made %d attempts, want 1", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	_, c := newServer(t)
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("Live: %v", err)
+	}
+	if err := c.Ready(ctx); err != nil {
+		t.Errorf("Ready: %v", err)
+	}
+	signUp(t, c, "Ada", "ada@example.com")
+	report, err := c.Health(ctx)
+	if err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if report.Status != "up" || report.Checks["database"].Status != "up" {
+		t.Errorf("Health returned %+v", report)
+	}
+}
+
+// TestUnversioned calls the deprecated routes without the version prefix, which
+// the client never uses
+func TestUnversioned(t *testing.T) {
+	server, c := newServer(t, func(cfg *config.Config) {
+		cfg.API.Deprecation = "2026-01-01"
+		cfg.API.Sunset = "2026-07-01"
+	})
+	tokens := signUp(t, c, "Ada", "ada@example.com")
+	list := func(server *httptest.Server, path string) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := list(server, "/organization/")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /organization/ = %d, want 200", resp.StatusCode)
+	}
+	for header, want := range map[string]string{
+		"Deprecation": "@1767225600",
+		"Sunset":      "Wed, 01 Jul 2026 00:00:00 GMT",
+		"Link":        `</v1/organization/>; rel="successor-version"`,
+	} {
+		if got := resp.Header.Get(header); got != want {
+			t.Errorf("GET /organization/ %s header = %q, want %q", header, got, want)
+		}
+	}
+	if resp := list(server, "/v1/organization/"); resp.StatusCode != http.StatusOK || resp.Header.Get("Deprecation") != "" {
+		t.Errorf("GET /v1/organization/ = %d with Deprecation %q, want 200 without", resp.StatusCode, resp.Header.Get("Deprecation"))
+	}
+
+	// Past the sunset the routes are switched off
+	server, _ = newServer(t, func(cfg *config.Config) { cfg.API.UnversionedRoutes = false })
+	if resp := list(server, "/organization/"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /organization/ with unversioned routes disabled = %d, want 404", resp.StatusCode)
+	}
+}
+
+// TestRouter serves the router alone, on repositories the test owns
+func TestRouter(t *testing.T) {
+	cfg := testConfig()
+	users := memory.NewUserRepository()
+	server := httptest.NewServer(routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         users,
+		Organizations: memory.NewOrganizationRepository(),
+		Logger:        contractLogger(t),
+	}))
+	t.Cleanup(server.Close)
+	c := client.New(server.URL, client.WithHTTPClient(server.Client()))
+
+	signUp(t, c, "Ada", "ada@example.com")
+	if user, err := users.GetUserByEmail(ctx, "ada@example.com"); err != nil || user == nil {
+		t.Errorf("SignUp did not reach the injected repository: %+v, %v", user, err)
+	}
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"}); err != nil {
+		t.Errorf("CreateOrganization: %v", err)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	_, c := newServer(t, func(cfg *config.Config) {
+		cfg.RateLimits.Enabled = true
+		cfg.RateLimits.Groups = map[string]config.RateLimitPolicy{
+			"user":         {RequestsPerMinute: 1, Burst: 3, Key: "ip"},
+			"organization": {RequestsPerMinute: 1, Burst: 2, Key: "user"},
+		}
+	})
+
+	// Sign-up and sign-in share the user group's bucket of the IP
+	signUp(t, c, "Ada", "ada@example.com")
+	if _, err := c.SignIn(ctx, "ada@example.com", "password1"); err != nil {
+		t.Fatalf("SignIn within the burst: %v", err)
+	}
+	var apiErr *client.Error
+	if _, err := c.SignIn(ctx, "ada@example.com", "password1"); !errors.As(err, &apiErr) || apiErr.Code != problem.CodeRateLimited {
+		t.Fatalf("SignIn past the burst: got %v, want %s", err, problem.CodeRateLimited)
+	}
+	if apiErr.Status != http.StatusTooManyRequests || apiErr.RetryAfter <= 0 || apiErr.RetryAfter > time.Minute {
+		t.Errorf("SignIn past the burst returned %+v, want 429 with a Retry-After of at most a minute", apiErr)
+	}
+
+	// The organization group has its own bucket per user, the IP's is exhausted
+	if _, err := c.ListOrganizations(ctx); err != nil {
+		t.Fatalf("ListOrganizations: %v", err)
+	}
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"}); err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+	if _, err := c.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Other"}); client.Code(err) != problem.CodeRateLimited {
+		t.Errorf("CreateOrganization past the burst: got %v, want %s", err, problem.CodeRateLimited)
+	}
+}
+
+func TestOrganizationRateLimits(t *testing.T) {
+	server, _ := newServer(t, func(cfg *config.Config) {
+		cfg.RateLimits.Enabled = true
+		cfg.RateLimits.Groups = map[string]config.RateLimitPolicy{
+			"organization": {RequestsPerMinute: 1, Burst: 3, Key: "organization"},
+		}
+	})
+	// Retrying would wait for the bucket to refill
+	noRetries := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1})
+	ada := client.New(server.URL, noRetries)
+	signUp(t, ada, "Ada", "ada@example.com")
+	id, err := ada.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+
+	// An outsider is limited by user, their requests leave the organization's
+	// bucket untouched
+	eve := client.New(server.URL, noRetries)
+	signUp(t, eve, "Eve", "eve@example.com")
+	for i := 0; i < 3; i++ {
+		if _, err := eve.GetOrganization(ctx, id); client.Code(err) != client.CodeNotMember {
+			t.Fatalf("GetOrganization by an outsider: got %v, want %s", err, client.CodeNotMember)
+		}
+	}
+	if _, err := eve.GetOrganization(ctx, id); client.Code(err) != problem.CodeRateLimited {
+		t.Fatalf("GetOrganization by an outsider past the burst: got %v, want %s", err, problem.CodeRateLimited)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := ada.GetOrganization(ctx, id); err != nil {
+			t.Fatalf("GetOrganization by a member: %v", err)
+		}
+	}
+	if _, err := ada.GetOrganization(ctx, id); client.Code(err) != problem.CodeRateLimited {
+		t.Errorf("GetOrganization by a member past the burst: got %v, want %s", err, problem.CodeRateLimited)
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	app, server, c := newApp(t)
+	// Platform admins are only created from the command line
+	err := app.Store.Users.CreateUser(ctx, &models.User{Name: "Root", Email: "root@example.com", Password: "password1", PlatformAdmin: true})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := c.SignIn(ctx, "root@example.com", "password1"); err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+
+	ada := client.New(server.URL)
+	signUp(t, ada, "Ada", "ada@example.com")
+	if _, err := ada.AdminListUsers(ctx, ""); client.Code(err) != client.CodePlatformAdminRequired {
+		t.Errorf("AdminListUsers by a regular user: got %v, want %s", err, client.CodePlatformAdminRequired)
+	}
+	id, err := ada.CreateOrganization(ctx, dto.OrganizationRequest{Name: "Acme", Description: "Rockets"})
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+
+	users, err := c.AdminListUsers(ctx, "ADA@")
+	if err != nil || len(users) != 1 || users[0].Email != "ada@example.com" || users[0].PlatformAdmin {
+		t.Fatalf("AdminListUsers(ADA@) = %+v, %v, want only Ada", users, err)
+	}
+	adaID := users[0].ID
+	if all, err := c.AdminListUsers(ctx, ""); err != nil || len(all) != 2 {
+		t.Errorf("AdminListUsers = %+v, %v, want both users", all, err)
+	}
+	orgs, err := c.AdminListOrganizations(ctx, "rocket", false)
+	if err != nil || len(orgs) != 1 || orgs[0].ID != id || orgs[0].DeletedAt != nil {
+		t.Errorf("AdminListOrganizations(rocket) = %+v, %v, want only %s", orgs, err, id)
+	}
+
+	// Impersonation acts as Ada, without refresh and without admin access
+	impersonation, err := c.AdminImpersonate(ctx, adaID, "support ticket 42")
+	if err != nil {
+		t.Fatalf("AdminImpersonate: %v", err)
+	}
+	if impersonation.User.ID != adaID || !impersonation.ExpiresAt.After(time.Now()) {
+		t.Errorf("AdminImpersonate returned %+v", impersonation)
+	}
+	as := client.New(server.URL, client.WithTokens(client.Tokens{AccessToken: impersonation.AccessToken, RefreshToken: impersonation.AccessToken}))
+	if org, err := as.GetOrganization(ctx, id); err != nil || org.Name != "Acme" {
+		t.Errorf("GetOrganization as Ada = %+v, %v", org, err)
+	}
+	if _, err := as.AdminListUsers(ctx, ""); client.Code(err) != client.CodeImpersonationNotAllowed {
+		t.Errorf("AdminListUsers while impersonating: got %v, want %s", err, client.CodeImpersonationNotAllowed)
+	}
+	if err := as.Refresh(ctx); client.Code(err) != problem.CodeInvalidRefreshToken {
+		t.Errorf("Refresh with an impersonation token: got %v, want %s", err, problem.CodeInvalidRefreshToken)
+	}
+	if _, err := c.AdminImpersonate(ctx, adaID, " "); client.Code(err) != problem.CodeValidationFailed {
+		t.Errorf("AdminImpersonate without a reason: got %v, want validation_failed", err)
+	}
+
+	// Suspension and deactivation take effect on the tokens already issued
+	root, err := c.AdminListUsers(ctx, "root@")
+	if err != nil || len(root) != 1 {
+		t.Fatalf("AdminListUsers(root@) = %+v, %v", root, err)
+	}
+	if _, err := c.AdminSuspendUser(ctx, root[0].ID); client.Code(err) != client.CodeCannotSuspendSelf {
+		t.Errorf("AdminSuspendUser of oneself: got %v, want %s", err, client.CodeCannotSuspendSelf)
+	}
+	for _, tc := range []struct {
+		state string
+		set   func(context.Context, string) (*dto.AdminUserResponse, error)
+		want  error
+	}{
+		{models.UserSuspended, c.AdminSuspendUser, repository.ErrUserSuspended},
+		{models.UserDeactivated, c.AdminDeactivateUser, repository.ErrUserDeactivated},
+	} {
+		if user, err := tc.set(ctx, adaID); err != nil || user.State != tc.state {
+			t.Fatalf("setting %s = %+v, %v", tc.state, user, err)
+		}
+		if _, err := ada.GetOrganization(ctx, id); !errors.Is(err, tc.want) {
+			t.Errorf("GetOrganization while %s: got %v, want %v", tc.state, err, tc.want)
+		}
+		if err := ada.Refresh(ctx); !errors.Is(err, tc.want) {
+			t.Errorf("Refresh while %s: got %v, want %v", tc.state, err, tc.want)
+		}
+		if _, err := ada.SignIn(ctx, "ada@example.com", "password1"); !errors.Is(err, tc.want) {
+			t.Errorf("SignIn while %s: got %v, want %v", tc.state, err, tc.want)
+		}
+		if _, err := c.AdminImpersonate(ctx, adaID, "support"); client.Code(err) != client.CodeImpersonationNotAllowed {
+			t.Errorf("AdminImpersonate while %s: got %v, want %s", tc.state, err, client.CodeImpersonationNotAllowed)
+		}
+	}
+	// Inactive users keep their memberships
+	if orgs, err := c.AdminListOrganizations(ctx, "ada@", false); err != nil || len(orgs) != 1 || len(orgs[0].Members) != 1 {
+		t.Errorf("AdminListOrganizations(ada@) while deactivated = %+v, %v", orgs, err)
+	}
+	if user, err := c.AdminReinstateUser(ctx, adaID); err != nil || user.State != models.UserActive {
+		t.Fatalf("AdminReinstateUser = %+v, %v", user, err)
+	}
+	if _, err := ada.GetOrganization(ctx, id); err != nil {
+		t.Errorf("GetOrganization after reinstatement: %v", err)
+	}
+	if _, err := ada.SignIn(ctx, "ada@example.com", "password1"); err != nil {
+		t.Errorf("SignIn after reinstatement: %v", err)
+	}
+	if _, err := c.AdminSuspendUser(ctx, primitive.NewObjectID().Hex()); client.Code(err) != client.CodeUserNotFound {
+		t.Errorf("AdminSuspendUser of an unknown user: got %v, want %s", err, client.CodeUserNotFound)
+	}
+
+	// Force delete and restore
+	if err := c.AdminForceDeleteOrganization(ctx, id); err != nil {
+		t.Fatalf("AdminForceDeleteOrganization: %v", err)
+	}
+	if _, err := ada.GetOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNotFound) {
+		t.Errorf("GetOrganization after force delete: got %v, want ErrOrganizationNotFound", err)
+	}
+	deleted, err := c.AdminListOrganizations(ctx, "", true)
+	if err != nil || len(deleted) != 1 || deleted[0].ID != id || deleted[0].DeletedAt == nil {
+		t.Errorf("AdminListOrganizations(deleted) = %+v, %v, want only %s", deleted, err, id)
+	}
+	restored, err := c.AdminRestoreOrganization(ctx, id)
+	if err != nil || restored.Name != "Acme" || len(restored.Members) != 1 || restored.DeletedAt != nil {
+		t.Fatalf("AdminRestoreOrganization = %+v, %v", restored, err)
+	}
+	if _, err := c.AdminRestoreOrganization(ctx, id); !errors.Is(err, repository.ErrOrganizationNotFound) {
+		t.Errorf("AdminRestoreOrganization twice: got %v, want ErrOrganizationNotFound", err)
+	}
+	if org, err := ada.GetOrganization(ctx, id); err != nil || org.Name != "Acme" {
+		t.Errorf("GetOrganization after restore = %+v, %v", org, err)
+	}
+}
+
+// flakyTransport fails every odd attempt with a network error
+type flakyTransport struct {
+	next     http.RoundTripper
+	attempts atomic.Int32
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.attempts.Add(1)%2 == 1 {
+		return nil, errors.New("connection reset by peer")
+	}
+	return f.next.RoundTrip(req)
+}
@@ -0,0 +1,163 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"Go-api/pkg"
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/client"
+	"Go-api/pkg/config"
+	"Go-api/pkg/logging"
+)
+
+// jwtSecret signs the tokens of the servers under test
+const jwtSecret = "e2e-secret-e2e-secret-e2e-secret"
+
+var ctx = context.Background()
+
+// testConfig is the configuration of the servers under test: the in-memory
+// database, no rate limits, as tests sign in more often than the defaults
+// allow, and every request and response checked against the OpenAPI document
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Database.Driver = "memory"
+	cfg.Auth.JWTSecret = jwtSecret
+	cfg.Logging.Level = "error"
+	cfg.Metrics.Enabled = false
+	cfg.RateLimits.Enabled = false
+	cfg.OpenAPI.ValidateRequests = true
+	cfg.OpenAPI.ValidateResponses = true
+	return cfg
+}
+
+// contractLogger returns a logger that fails t for every response the
+// Contract middleware finds not to match the OpenAPI document, and drops
+// everything else
+func contractLogger(t testing.TB) *slog.Logger {
+	return slog.New(contractHandler{t: t})
+}
+
+type contractHandler struct {
+	t testing.TB
+}
+
+func (h contractHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h contractHandler) Handle(_ context.Context, record slog.Record) error {
+	if record.Message != "response does not match the OpenAPI document" {
+		return nil
+	}
+	var details []string
+	record.Attrs(func(attr slog.Attr) bool {
+		details = append(details, attr.String())
+		return true
+	})
+	h.t.Errorf("%s: %s", record.Message, strings.Join(details, " "))
+	return nil
+}
+
+func (h contractHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h contractHandler) WithGroup(string) slog.Handler { return h }
+
+// newServer serves the application on the in-memory database until the test
+// ends and returns a client for it. configure can change the configuration
+// before the application is built.
+func newServer(t testing.TB, configure ...func(*config.Config)) (*httptest.Server, *client.Client) {
+	t.Helper()
+	_, server, c := newApp(t, configure...)
+	return server, c
+}
+
+// newApp is newServer that also returns the application, whose store tests can
+// fill directly
+func newApp(t testing.TB, configure ...func(*config.Config)) (*pkg.App, *httptest.Server, *client.Client) {
+	t.Helper()
+	cfg := testConfig()
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	levels := &logging.Levels{}
+	if err := levels.Set(cfg.Logging); err != nil {
+		t.Fatalf("invalid log levels: %v", err)
+	}
+	app, err := pkg.NewApp(contractLogger(t), levels, cfg, config.Flags{})
+	if err != nil {
+		t.Fatalf("failed to build application: %v", err)
+	}
+	server := httptest.NewServer(app.Engine)
+	t.Cleanup(func() {
+		server.Close()
+		app.Shutdown(context.Background())
+	})
+	return app, server, client.New(server.URL, client.WithHTTPClient(server.Client()))
+}
+
+// signUp registers a user with a valid password and signs c in as them
+func signUp(t testing.TB, c *client.Client, name, email string) client.Tokens {
+	t.Helper()
+	if err := c.SignUp(ctx, dto.SignUpRequest{Name: name, Email: email, Password: "password1"}); err != nil {
+		t.Fatalf("SignUp(%s): %v", email, err)
+	}
+	tokens, err := c.SignIn(ctx, email, "password1")
+	if err != nil {
+		t.Fatalf("SignIn(%s): %v", email, err)
+	}
+	return tokens
+}
+
+// forbiddenKeys must never appear as a JSON key, at any depth
+var forbiddenKeys = []string{"password", "hash", "secret", "salt"}
+
+// snakeCase is the naming of every response key
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// bcryptPrefix starts every password hash the repositories store
+const bcryptPrefix = "$2a$"
+
+// assertNoSecrets fails t if the JSON body has a key naming a secret, a key that
+// is not snake_case, or a string containing a bcrypt hash or any of secrets
+func assertNoSecrets(t testing.TB, body []byte, secrets ...string) {
+	t.Helper()
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, body)
+	}
+	walk(t, "$", doc, append(secrets, bcryptPrefix))
+}
+
+func walk(t testing.TB, path string, value any, secrets []string) {
+	t.Helper()
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			lower := strings.ToLower(key)
+			for _, forbidden := range forbiddenKeys {
+				if strings.Contains(lower, forbidden) {
+					t.Errorf("%s.%s: response exposes a secret field", path, key)
+				}
+			}
+			if !snakeCase.MatchString(key) {
+				t.Errorf("%s.%s: key is not snake_case", path, key)
+			}
+			walk(t, path+"."+key, inner, secrets)
+		}
+	case []any:
+		for _, inner := range v {
+			walk(t, path+"[]", inner, secrets)
+		}
+	case string:
+		for _, secret := range secrets {
+			if secret != "" && strings.Contains(v, secret) {
+				t.Errorf("%s: response contains a secret value", path)
+			}
+		}
+	}
+}
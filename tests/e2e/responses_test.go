@@ -0,0 +1,234 @@
+// Package e2e serves the production router on the in-memory repositories and
+// exercises it over HTTP.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"Go-api/pkg/api/dto"
+	"Go-api/pkg/api/routes"
+	"Go-api/pkg/config"
+	"Go-api/pkg/database/memory"
+	"Go-api/pkg/database/mongodb/models"
+)
+
+// api calls a server as one user
+type api struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+}
+
+// call sends body as JSON and returns the response with its body, failing the
+// test unless the status is want
+func (a *api) call(method, path string, header http.Header, body any, want int) (*http.Response, []byte) {
+	a.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("Marshal: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.server.URL+path, reader)
+	if err != nil {
+		a.t.Fatalf("NewRequest: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	resp, err := a.server.Client().Do(req)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if resp.StatusCode != want {
+		a.t.Fatalf("%s %s = %d, want %d: %s", method, path, resp.StatusCode, want, data)
+	}
+	return resp, data
+}
+
+// TestResponsesHaveNoSecrets calls every handler that renders users,
+// organizations or tokens and checks no response exposes a password or its hash
+func TestResponsesHaveNoSecrets(t *testing.T) {
+	const password = "correct-horse-1"
+	cfg := testConfig()
+	users := memory.NewUserRepository()
+	server := httptest.NewServer(routes.New(routes.Dependencies{
+		Config:        func() *config.Config { return cfg },
+		Users:         users,
+		Organizations: memory.NewOrganizationRepository(),
+		Logger:        contractLogger(t),
+	}))
+	t.Cleanup(server.Close)
+
+	// Platform admins are only created from the command line
+	root := &models.User{Name: "Root", Email: "root@example.com", Password: password, PlatformAdmin: true}
+	if err := users.CreateUser(context.Background(), root); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	secrets := []string{password, root.Password}
+	check := func(name string, body []byte) {
+		t.Run(name, func(t *testing.T) { assertNoSecrets(t, body, secrets...) })
+	}
+	signIn := func(email string) *api {
+		anonymous := &api{t: t, server: server}
+		_, body := anonymous.call(http.MethodPost, "/v1/user/signin", nil, map[string]string{"email": email, "password": password}, http.StatusOK)
+		check("SignIn", body)
+		var tokens struct {
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+		}
+		json.Unmarshal(body, &tokens)
+		_, body = anonymous.call(http.MethodPost, "/v1/user/refresh", nil, map[string]string{"refresh_token": tokens.RefreshToken}, http.StatusOK)
+		check("Refresh", body)
+		return &api{t: t, server: server, token: tokens.AccessToken}
+	}
+
+	_, body := (&api{t: t, server: server}).call(http.MethodPost, "/v1/user/signup", nil,
+		map[string]string{"name": "Ada", "email": "ada@example.com", "password": password}, http.StatusCreated)
+	check("SignUp", body)
+	ada := signIn("ada@example.com")
+	admin := signIn("root@example.com")
+
+	_, body = ada.call(http.MethodPost, "/v1/organization/", nil, map[string]string{"name": "Acme", "description": "Rockets"}, http.StatusCreated)
+	check("CreateOrg", body)
+	var created struct {
+		OrganizationID string `json:"organization_id"`
+	}
+	json.Unmarshal(body, &created)
+	org := "/v1/organization/" + created.OrganizationID
+
+	_, body = ada.call(http.MethodGet, "/v1/organization/", nil, nil, http.StatusOK)
+	check("ListOrgs", body)
+	resp, body := ada.call(http.MethodGet, org, nil, nil, http.StatusOK)
+	check("GetOrgByID", body)
+	resp, body = ada.call(http.MethodPatch, org, http.Header{"If-Match": {resp.Header.Get("ETag")}}, map[string]string{"description": "Spaceships"}, http.StatusOK)
+	check("PatchOrg", body)
+	_, body = ada.call(http.MethodPut, org, http.Header{"If-Match": {resp.Header.Get("ETag")}}, map[string]string{"name": "Acme", "description": "Rockets"}, http.StatusOK)
+	check("UpdateOrg", body)
+	_, body = ada.call(http.MethodPost, org+"/invite", nil, map[string]string{"user_email": "root@example.com"}, http.StatusOK)
+	check("InviteUser", body)
+	_, body = ada.call(http.MethodDelete, org+"/members/root@example.com", nil, nil, http.StatusOK)
+	check("RemoveMember", body)
+
+	_, body = admin.call(http.MethodGet, "/v1/admin/users", nil, nil, http.StatusOK)
+	check("AdminListUsers", body)
+	var listed struct {
+		Users []struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		} `json:"users"`
+	}
+	json.Unmarshal(body, &listed)
+	var adaID string
+	for _, user := range listed.Users {
+		if user.Email == "ada@example.com" {
+			adaID = user.ID
+		}
+	}
+	if adaID == "" {
+		t.Fatalf("Ada is not listed: %s", body)
+	}
+	_, body = admin.call(http.MethodPost, "/v1/admin/users/"+adaID+"/impersonate", nil, map[string]string{"reason": "support ticket 42"}, http.StatusOK)
+	check("AdminImpersonate", body)
+	_, body = admin.call(http.MethodPost, "/v1/admin/users/"+adaID+"/suspend", nil, nil, http.StatusOK)
+	check("AdminSuspendUser", body)
+	_, body = admin.call(http.MethodPost, "/v1/admin/users/"+adaID+"/reinstate", nil, nil, http.StatusOK)
+	check("AdminReinstateUser", body)
+	_, body = admin.call(http.MethodGet, "/v1/admin/organizations", nil, nil, http.StatusOK)
+	check("AdminListOrgs", body)
+	_, body = admin.call(http.MethodDelete, "/v1/admin/organizations/"+created.OrganizationID, nil, nil, http.StatusOK)
+	check("AdminForceDeleteOrg", body)
+	_, body = admin.call(http.MethodPost, "/v1/admin/organizations/"+created.OrganizationID+"/restore", nil, nil, http.StatusOK)
+	check("AdminRestoreOrg", body)
+
+	// Errors render problem documents, which must not echo the request either
+	_, body = (&api{t: t, server: server}).call(http.MethodPost, "/v1/user/signin", nil,
+		map[string]string{"email": "ada@example.com", "password": password + "x"}, http.StatusUnauthorized)
+	check("SignInFailed", body)
+}
+
+// TestResponseTypesHaveNoSecrets renders every response type from fully
+// populated models, including a password hash, and checks none of them leaks it
+func TestResponseTypesHaveNoSecrets(t *testing.T) {
+	const hash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	user := &models.User{
+		ID:       primitive.NewObjectID(),
+		Name:     "Ada",
+		Email:    "ada@example.com",
+		Password: hash,
+	}
+	org := &models.Organization{
+		ID:          primitive.NewObjectID(),
+		Name:        "acme",
+		Description: "description",
+		OrganizationMembers: []models.OrganizationMember{
+			{Name: user.Name, Email: user.Email, AccessLevel: 1},
+		},
+		Version: 3,
+	}
+	deletedAt := time.Now()
+	deleted := *org
+	deleted.DeletedAt = &deletedAt
+
+	responses := map[string]dto.Response{
+		"MessageResponse":               &dto.MessageResponse{Message: "ok"},
+		"UserResponse":                  dto.NewUserResponse(user),
+		"TokenResponse":                 &dto.TokenResponse{Message: "ok", AccessToken: "access", RefreshToken: "refresh"},
+		"OrganizationResponse":          dto.NewOrganizationResponse(org),
+		"OrganizationListResponse":      dto.NewOrganizationListResponse([]models.Organization{*org}),
+		"OrganizationCreatedResponse":   &dto.OrganizationCreatedResponse{OrganizationID: org.ID.Hex()},
+		"AdminUserResponse":             dto.NewAdminUserResponse(user),
+		"UserListResponse":              dto.NewUserListResponse([]models.User{*user}),
+		"AdminOrganizationResponse":     dto.NewAdminOrganizationResponse(&deleted),
+		"AdminOrganizationListResponse": dto.NewAdminOrganizationListResponse([]models.Organization{*org, deleted}),
+		"ImpersonationResponse": &dto.ImpersonationResponse{
+			Message:     "ok",
+			AccessToken: "access",
+			ExpiresAt:   time.Now(),
+			User:        *dto.NewUserResponse(user),
+		},
+	}
+	for name, resp := range responses {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			assertNoSecrets(t, body, hash)
+		})
+	}
+
+	t.Run("Model", func(t *testing.T) {
+		// The model itself must not encode its hash either
+		body, err := json.Marshal(user)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if strings.Contains(string(body), hash) {
+			t.Fatal("models.User encodes its password hash")
+		}
+	})
+}
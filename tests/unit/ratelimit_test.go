@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	database "Go-api/pkg/database/mongodb"
+	mongorepo "Go-api/pkg/database/mongodb/repository"
+	"Go-api/pkg/logging"
+	"Go-api/pkg/ratelimit"
+)
+
+// clock is the time of a store under test, BSON dates keep milliseconds
+type clock struct {
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Now().Truncate(time.Millisecond)}
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// testRateLimitStore checks the buckets of a store whose time is read from
+// the clock passed to newStore
+func testRateLimitStore(t *testing.T, newStore func(t *testing.T, now func() time.Time) ratelimit.Store) {
+	ctx := context.Background()
+	limit := ratelimit.Limit{PerMinute: 60, Burst: 3}
+	c := newClock()
+	store := newStore(t, c.Now)
+	take := func(key string, want ratelimit.Result) {
+		t.Helper()
+		got, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Take(%s): %v", key, err)
+		}
+		// MongoDB refills in floating point milliseconds
+		if got.Allowed != want.Allowed || got.Remaining != want.Remaining ||
+			!near(got.RetryAfter, want.RetryAfter) || !near(got.Reset, want.Reset) {
+			t.Errorf("Take(%s) = %+v, want %+v", key, got, want)
+		}
+	}
+
+	// A new bucket starts full
+	take("a", ratelimit.Result{Allowed: true, Remaining: 2, Reset: time.Second})
+	take("a", ratelimit.Result{Allowed: true, Remaining: 1, Reset: 2 * time.Second})
+	take("a", ratelimit.Result{Allowed: true, Remaining: 0, Reset: 3 * time.Second})
+	take("a", ratelimit.Result{Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second})
+
+	// Other keys have buckets of their own
+	take("b", ratelimit.Result{Allowed: true, Remaining: 2, Reset: time.Second})
+
+	// Tokens refill over time
+	c.Advance(500 * time.Millisecond)
+	take("a", ratelimit.Result{Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 2500 * time.Millisecond})
+	c.Advance(500 * time.Millisecond)
+	take("a", ratelimit.Result{Allowed: true, Remaining: 0, Reset: 3 * time.Second})
+
+	// up to the burst
+	c.Advance(time.Minute)
+	take("a", ratelimit.Result{Allowed: true, Remaining: 2, Reset: time.Second})
+}
+
+func near(got, want time.Duration) bool {
+	return (got - want).Abs() < time.Millisecond
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	testRateLimitStore(t, func(t *testing.T, now func() time.Time) ratelimit.Store {
+		store := ratelimit.NewMemoryStore()
+		store.Now = now
+		return store
+	})
+}
+
+// TestMongoRateLimitStore runs against the server at MONGO_URI, in a migrated
+// database of its own that is dropped afterwards
+func TestMongoRateLimitStore(t *testing.T) {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+	ctx := context.Background()
+	db, err := database.Connect(ctx, uri, "")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mdb := db.Client.Database("goapi_test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { mdb.Drop(context.Background()) })
+	if err := database.NewMigrator(logging.Discard(), mdb).Migrate(ctx, false); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	var c func() time.Time
+	testRateLimitStore(t, func(t *testing.T, now func() time.Time) ratelimit.Store {
+		c = now
+		store := mongorepo.NewRateLimitStore(mdb)
+		store.Now = now
+		return store
+	})
+
+	// Each key is upserted once and expires when it is full again
+	if n, err := mdb.Collection("rate_limits").CountDocuments(ctx, bson.M{}); err != nil || n != 2 {
+		t.Fatalf("rate_limits holds %d buckets, %v, want a and b", n, err)
+	}
+	var bucket struct {
+		ExpiresAt time.Time `bson:"expires_at"`
+	}
+	if err := mdb.Collection("rate_limits").FindOne(ctx, bson.M{"_id": "a"}).Decode(&bucket); err != nil {
+		t.Fatalf("FindOne(a): %v", err)
+	}
+	if want := c().Add(time.Second); !near(bucket.ExpiresAt.Sub(want), 0) {
+		t.Errorf("a expires at %v, want %v", bucket.ExpiresAt, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Package unit runs the repository conformance suite against every backend
+// that can run in a test: the in-memory one always, the others when their
+// database is available.
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	// Register the "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+
+	"Go-api/pkg/database/memory"
+	database "Go-api/pkg/database/mongodb"
+	mongorepo "Go-api/pkg/database/mongodb/repository"
+	"Go-api/pkg/database/postgres"
+	"Go-api/pkg/database/repository"
+	"Go-api/pkg/database/repository/repotest"
+	"Go-api/pkg/logging"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	t.Run("Users", func(t *testing.T) {
+		repotest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+			return memory.NewUserRepository()
+		})
+	})
+	t.Run("Organizations", func(t *testing.T) {
+		repotest.TestOrganizationRepository(t, func(t *testing.T) repository.OrganizationRepository {
+			return memory.NewOrganizationRepository()
+		})
+	})
+}
+
+// TestPostgresRepositories runs the PostgreSQL backend on SQLite, each
+// repository in a migrated in-memory database of its own
+func TestPostgresRepositories(t *testing.T) {
+	newDatabase := func(t *testing.T) *sql.DB {
+		t.Helper()
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		// Every connection to :memory: opens a database of its own
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() { db.Close() })
+		if err := postgres.Migrate(context.Background(), db); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		return db
+	}
+	t.Run("Users", func(t *testing.T) {
+		repotest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+			return postgres.NewUserRepository(newDatabase(t))
+		})
+	})
+	t.Run("Organizations", func(t *testing.T) {
+		repotest.TestOrganizationRepository(t, func(t *testing.T) repository.OrganizationRepository {
+			return postgres.NewOrganizationRepository(newDatabase(t))
+		})
+	})
+}
+
+// TestMongoRepositories runs against the server at MONGO_URI, each repository
+// in a migrated database of its own that is dropped afterwards
+func TestMongoRepositories(t *testing.T) {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+	ctx := context.Background()
+	db, err := database.Connect(ctx, uri, "")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	newDatabase := func(t *testing.T) *mongo.Database {
+		t.Helper()
+		mdb := db.Client.Database("goapi_test_" + primitive.NewObjectID().Hex())
+		t.Cleanup(func() { mdb.Drop(context.Background()) })
+		if err := database.NewMigrator(logging.Discard(), mdb).Migrate(ctx, false); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		return mdb
+	}
+	t.Run("Users", func(t *testing.T) {
+		repotest.TestUserRepository(t, func(t *testing.T) repository.UserRepository {
+			return mongorepo.NewUserRepository(newDatabase(t))
+		})
+	})
+	t.Run("Organizations", func(t *testing.T) {
+		repotest.TestOrganizationRepository(t, func(t *testing.T) repository.OrganizationRepository {
+			return mongorepo.NewOrganizationRepository(newDatabase(t))
+		})
+	})
+}